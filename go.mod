@@ -1,9 +1,20 @@
 module github.com/apparentlymart/terraform-provider-testing
 
 require (
+	github.com/antchfx/xpath v1.1.10
 	github.com/apparentlymart/go-test-anything v0.0.0-20190323155717-3de3617c9489
 	github.com/apparentlymart/terraform-sdk v0.0.0-20190330211852-6a03d743cd24
+	github.com/go-sql-driver/mysql v1.5.0
+	github.com/hashicorp/go-version v1.2.0
+	github.com/hashicorp/hcl2 v0.0.0-20190416162332-2c5a4b7d729a
+	github.com/jmespath/go-jmespath v0.3.0
+	github.com/lib/pq v1.1.1
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/zclconf/go-cty v0.0.0-20190317012026-9463876af40c
+	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2
+	golang.org/x/net v0.0.0-20190328230028-74de082e2cca
+	google.golang.org/grpc v1.14.0
+	gopkg.in/yaml.v2 v2.2.2
 )
 
 go 1.13
@@ -1,10 +1,58 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+
 	provider "github.com/apparentlymart/terraform-provider-testing/testing"
 	tfsdk "github.com/apparentlymart/terraform-sdk"
 )
 
+// version and commit identify the build. Release builds set them using
+// the linker, like this:
+//
+//	go build -ldflags "-X main.version=0.0.2 -X main.commit=$(git rev-parse HEAD)"
+var (
+	version = "dev"
+	commit  = ""
+)
+
 func main() {
+	// Terraform runs the plugin without any arguments, so flags are only
+	// for people running the executable directly.
+	showVersion := flag.Bool("version", false, "print the provider version and exit")
+	showSchema := flag.Bool("schema", false, "print the provider's schema as JSON and exit")
+	validate := flag.Bool("validate", false, "check the provider's data source definitions and exit")
+	flag.Parse()
+	if *showVersion {
+		fmt.Fprintf(os.Stdout, "terraform-provider-testing %s", version)
+		if commit != "" {
+			fmt.Fprintf(os.Stdout, " (%s)", commit)
+		}
+		fmt.Fprintln(os.Stdout)
+		return
+	}
+	if *showSchema {
+		src, err := provider.ProviderSchemaJSON()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to describe provider schema: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stdout, "%s\n", src)
+		return
+	}
+	if *validate {
+		if errs := provider.CheckDataResourceTypes(); len(errs) != 0 {
+			fmt.Fprintln(os.Stderr, "Invalid provider implementation:")
+			for _, err := range errs {
+				fmt.Fprintf(os.Stderr, "  - %s\n", err)
+			}
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stdout, "The provider's data source definitions are valid.")
+		return
+	}
+
 	tfsdk.ServeProviderPlugin(provider.Provider())
 }
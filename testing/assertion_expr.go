@@ -0,0 +1,134 @@
+package testing
+
+import (
+	"fmt"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// assertionExprFunctions are the functions available for use in the
+// condition expressions of "expr" assertion blocks.
+//
+// This is a subset of the functions available in the main Terraform language,
+// limited to those that are useful for writing assertions and that have
+// implementations available in the cty standard library.
+var assertionExprFunctions = map[string]function.Function{
+	"abs":        stdlib.AbsoluteFunc,
+	"coalesce":   stdlib.CoalesceFunc,
+	"concat":     stdlib.ConcatFunc,
+	"format":     stdlib.FormatFunc,
+	"formatlist": stdlib.FormatListFunc,
+	"jsondecode": stdlib.JSONDecodeFunc,
+	"jsonencode": stdlib.JSONEncodeFunc,
+	"length":     stdlib.LengthFunc,
+	"lower":      stdlib.LowerFunc,
+	"max":        stdlib.MaxFunc,
+	"min":        stdlib.MinFunc,
+	"reverse":    stdlib.ReverseFunc,
+	"strlen":     stdlib.StrlenFunc,
+	"substr":     stdlib.SubstrFunc,
+	"upper":      stdlib.UpperFunc,
+}
+
+func parseAssertionExpr(src string) (hclsyntax.Expression, hcl.Diagnostics) {
+	return hclsyntax.ParseExpression([]byte(src), "condition", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+}
+
+// validateAssertionExpr is a ValidateFn for the condition argument in "expr"
+// assertion blocks, which checks only that the expression is syntactically
+// valid.
+func validateAssertionExpr(src string) tfsdk.Diagnostics {
+	_, hclDiags := parseAssertionExpr(src)
+	return convertHCLDiagnostics(hclDiags, "condition expression", nil)
+}
+
+// evalAssertionExpr evaluates the given condition expression with the given
+// "got" value and additional variables in scope, returning true if the
+// condition holds.
+//
+// Any diagnostics returned are relative to the given path, which should be the
+// path of the "expr" block being evaluated. If error diagnostics are returned
+// then the boolean result is meaningless.
+func evalAssertionExpr(src string, got, vars cty.Value, path cty.Path) (bool, tfsdk.Diagnostics) {
+	var diags tfsdk.Diagnostics
+
+	expr, hclDiags := parseAssertionExpr(src)
+	diags = diags.Append(convertHCLDiagnostics(hclDiags, "condition expression", path.GetAttr("condition")))
+	if diags.HasErrors() {
+		return false, diags
+	}
+
+	if got == cty.NilVal {
+		got = cty.NullVal(cty.DynamicPseudoType)
+	}
+	varVals := map[string]cty.Value{
+		"got": got,
+	}
+	if vars != cty.NilVal && !vars.IsNull() {
+		ty := vars.Type()
+		if !(ty.IsObjectType() || ty.IsMapType()) {
+			diags = diags.Append(tfsdk.Diagnostic{
+				Severity: tfsdk.Error,
+				Summary:  "Invalid assertion variables",
+				Detail:   fmt.Sprintf("The vars argument must be an object or map, not %s.", ty.FriendlyName()),
+				Path:     path.GetAttr("vars"),
+			})
+			return false, diags
+		}
+		for it := vars.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			name := k.AsString()
+			if !hclsyntax.ValidIdentifier(name) || name == "got" {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid assertion variables",
+					Detail:   fmt.Sprintf("Cannot declare a variable named %q: variable names must be valid identifiers and must not be \"got\".", name),
+					Path:     path.GetAttr("vars"),
+				})
+				continue
+			}
+			varVals[name] = v
+		}
+		if diags.HasErrors() {
+			return false, diags
+		}
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: varVals,
+		Functions: assertionExprFunctions,
+	}
+	result, hclDiags := expr.Value(ctx)
+	diags = diags.Append(convertHCLDiagnostics(hclDiags, "condition expression", path.GetAttr("condition")))
+	if diags.HasErrors() {
+		return false, diags
+	}
+
+	result, err := convert.Convert(result, cty.Bool)
+	if err != nil {
+		diags = diags.Append(tfsdk.Diagnostic{
+			Severity: tfsdk.Error,
+			Summary:  "Invalid condition result",
+			Detail:   fmt.Sprintf("The condition expression must return a boolean value: %s.", tfsdk.FormatError(err)),
+			Path:     path.GetAttr("condition"),
+		})
+		return false, diags
+	}
+	if !result.IsKnown() || result.IsNull() {
+		diags = diags.Append(tfsdk.Diagnostic{
+			Severity: tfsdk.Error,
+			Summary:  "Invalid condition result",
+			Detail:   "The condition expression must return either true or false, not null.",
+			Path:     path.GetAttr("condition"),
+		})
+		return false, diags
+	}
+
+	return result.True(), diags
+}
@@ -0,0 +1,60 @@
+package testing
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// writeBaseline saves the given values to a baseline file for
+// testing_baseline_compare to read in a later run.
+//
+// The file uses cty's JSON encoding for dynamically-typed values, which
+// records the type alongside the value so that, for example, a list of
+// strings is not confused with a tuple when it is read back.
+func writeBaseline(path string, values cty.Value) error {
+	buf, err := ctyjson.Marshal(values, cty.DynamicPseudoType)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(buf, '\n'))
+}
+
+// readBaseline reads values previously saved by writeBaseline.
+func readBaseline(path string) (cty.Value, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	v, err := ctyjson.Unmarshal(buf, cty.DynamicPseudoType)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("invalid baseline file: %s", err)
+	}
+	if _, err := baselineElements(v); err != nil {
+		return cty.NilVal, fmt.Errorf("invalid baseline file: %s", err)
+	}
+	return v, nil
+}
+
+// baselineElements returns the elements of the given object or map value,
+// which is how baseline values are always given.
+func baselineElements(v cty.Value) (map[string]cty.Value, error) {
+	ty := v.Type()
+	if !(ty.IsObjectType() || ty.IsMapType()) {
+		return nil, fmt.Errorf("must be an object or map value")
+	}
+	if v.IsNull() {
+		return nil, fmt.Errorf("must not be null")
+	}
+	if !v.IsWhollyKnown() {
+		return nil, fmt.Errorf("must not contain unknown values")
+	}
+	ret := make(map[string]cty.Value)
+	for it := v.ElementIterator(); it.Next(); {
+		k, ev := it.Element()
+		ret[k.AsString()] = ev
+	}
+	return ret, nil
+}
@@ -0,0 +1,423 @@
+package testing
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"sort"
+	"strings"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// userDataPart is one part of a cloud-init user data payload, which is
+// either the whole payload or one part of a MIME multipart archive.
+type userDataPart struct {
+	ContentType string
+	Filename    string
+	Content     []byte
+
+	// Location describes where the part appears in the payload, for use in
+	// error messages.
+	Location string
+}
+
+// userDataHeaders are the first-line markers that cloud-init uses to detect
+// the type of a user data payload that isn't a MIME archive.
+var userDataHeaders = []struct {
+	Prefix      string
+	ContentType string
+}{
+	// Longer prefixes must come before any prefix of them.
+	{"#cloud-config-archive", "text/cloud-config-archive"},
+	{"#cloud-config", "text/cloud-config"},
+	{"#cloud-boothook", "text/cloud-boothook"},
+	{"#include", "text/x-include-url"},
+	{"#part-handler", "text/part-handler"},
+	{"## template: jinja", "text/jinja2"},
+	{"#!", "text/x-shellscript"},
+}
+
+// parseUserData splits the given cloud-init user data into its parts,
+// decompressing it first if it is gzip-compressed as cloud-init allows.
+func parseUserData(src []byte) ([]userDataPart, error) {
+	if bytes.HasPrefix(src, []byte{0x1f, 0x8b}) {
+		r, err := gzip.NewReader(bytes.NewReader(src))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip compression: %s", err)
+		}
+		src, err = ioutil.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip compression: %s", err)
+		}
+	}
+
+	if isMIMEUserData(src) {
+		msg, err := mail.ReadMessage(bytes.NewReader(src))
+		if err != nil {
+			return nil, fmt.Errorf("invalid MIME message: %s", err)
+		}
+		return parseUserDataMIME(mail.Header(msg.Header), msg.Body, "")
+	}
+
+	firstLine := src
+	if i := bytes.IndexByte(src, '\n'); i >= 0 {
+		firstLine = src[:i]
+	}
+	for _, h := range userDataHeaders {
+		if bytes.HasPrefix(firstLine, []byte(h.Prefix)) {
+			return []userDataPart{{ContentType: h.ContentType, Content: src, Location: "user data"}}, nil
+		}
+	}
+	return nil, fmt.Errorf("unrecognized format: user data must either be a MIME multipart archive or start with a line like #cloud-config or #!, but the first line is %q", string(firstLine))
+}
+
+// isMIMEUserData returns true if the given user data seems to start with
+// MIME headers, rather than one of the cloud-init header lines.
+func isMIMEUserData(src []byte) bool {
+	sc := bufio.NewScanner(bytes.NewReader(src))
+	if !sc.Scan() {
+		return false
+	}
+	line := strings.ToLower(sc.Text())
+	return strings.HasPrefix(line, "content-type:") || strings.HasPrefix(line, "mime-version:")
+}
+
+func parseUserDataMIME(header mail.Header, body io.Reader, prefix string) ([]userDataPart, error) {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Type header: %s", err)
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		content, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		return []userDataPart{{ContentType: mediaType, Content: content, Location: "user data"}}, nil
+	}
+
+	var parts []userDataPart
+	mr := multipart.NewReader(body, params["boundary"])
+	for i := 1; ; i++ {
+		// NextPart decodes quoted-printable parts itself, removing their
+		// Content-Transfer-Encoding header, so only base64 is left for us
+		// to decode below.
+		p, err := mr.NextPart()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("invalid MIME multipart archive: %s", err)
+		}
+		location := fmt.Sprintf("%spart %d", prefix, i)
+		if p.FileName() != "" {
+			location = fmt.Sprintf("%s (%q)", location, p.FileName())
+		}
+
+		partType, _, err := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		if err != nil {
+			return nil, fmt.Errorf("%s has invalid Content-Type header: %s", location, err)
+		}
+		if strings.HasPrefix(partType, "multipart/") {
+			nested, err := parseUserDataMIME(mail.Header(p.Header), p, location+", ")
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, nested...)
+			continue
+		}
+		content, err := ioutil.ReadAll(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", location, err)
+		}
+		switch enc := strings.ToLower(p.Header.Get("Content-Transfer-Encoding")); enc {
+		case "", "7bit", "8bit", "binary":
+		case "base64":
+			content, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(content)), ""))
+			if err != nil {
+				return nil, fmt.Errorf("%s has invalid base64 content: %s", location, err)
+			}
+		default:
+			return nil, fmt.Errorf("%s has unsupported Content-Transfer-Encoding %q", location, enc)
+		}
+
+		parts = append(parts, userDataPart{
+			ContentType: partType,
+			Filename:    p.FileName(),
+			Content:     content,
+			Location:    location,
+		})
+	}
+	return parts, nil
+}
+
+// validateCloudConfig checks the given parsed cloud-config document against
+// the subset of the cloud-config schema that this provider knows about,
+// returning errors for problems cloud-init would reject or misinterpret and,
+// separately, warnings for top-level keys that cloud-init would ignore.
+//
+// Errors are cty.PathErrors relative to the document.
+func validateCloudConfig(doc cty.Value) (errs []error, unknownKeys []string) {
+	if doc.IsNull() {
+		// An empty document is valid, but does nothing.
+		return nil, nil
+	}
+	if !doc.Type().IsObjectType() {
+		return []error{cty.Path(nil).NewErrorf("must be a mapping, not %s", cloudConfigTypeName(doc))}, nil
+	}
+	for it := doc.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		key := k.AsString()
+		check, known := cloudConfigSchema[key]
+		if !known {
+			unknownKeys = append(unknownKeys, key)
+			continue
+		}
+		if check != nil {
+			errs = append(errs, check(v, cty.Path(nil).GetAttr(key))...)
+		}
+	}
+	sort.Strings(unknownKeys)
+	return errs, unknownKeys
+}
+
+// cloudConfigCheck validates the value of one key in a cloud-config document.
+type cloudConfigCheck func(v cty.Value, path cty.Path) []error
+
+// cloudConfigSchema describes the top-level cloud-config keys this provider
+// recognizes. Keys with a nil check are accepted with any value.
+var cloudConfigSchema = map[string]cloudConfigCheck{
+	"apt":                        ccMapping,
+	"apt_pipelining":             nil,
+	"bootcmd":                    ccCommands,
+	"ca_certs":                   ccMapping,
+	"ca-certs":                   ccMapping,
+	"chpasswd":                   ccMapping,
+	"device_aliases":             ccMapping,
+	"disable_ec2_metadata":       ccBool,
+	"disable_root":               ccBool,
+	"disk_setup":                 ccMapping,
+	"final_message":              ccString,
+	"fqdn":                       ccString,
+	"fs_setup":                   ccList(nil),
+	"groups":                     nil,
+	"growpart":                   ccMapping,
+	"hostname":                   ccString,
+	"keyboard":                   ccMapping,
+	"locale":                     ccString,
+	"manage_etc_hosts":           nil,
+	"manage_resolv_conf":         ccBool,
+	"merge_how":                  nil,
+	"merge_type":                 nil,
+	"mounts":                     ccList(ccList(nil)),
+	"ntp":                        ccMapping,
+	"output":                     ccMapping,
+	"package_reboot_if_required": ccBool,
+	"package_update":             ccBool,
+	"package_upgrade":            ccBool,
+	"packages":                   ccList(ccStringOrStrings),
+	"password":                   ccString,
+	"phone_home":                 ccMapping,
+	"power_state":                ccMapping,
+	"preserve_hostname":          ccBool,
+	"prefer_fqdn_over_hostname":  ccBool,
+	"random_seed":                ccMapping,
+	"resize_rootfs":              nil,
+	"resolv_conf":                ccMapping,
+	"rsyslog":                    nil,
+	"runcmd":                     ccCommands,
+	"snap":                       ccMapping,
+	"ssh_authorized_keys":        ccList(ccString),
+	"ssh_deletekeys":             ccBool,
+	"ssh_genkeytypes":            ccList(ccString),
+	"ssh_keys":                   ccMapping,
+	"ssh_pwauth":                 nil,
+	"swap":                       ccMapping,
+	"timezone":                   ccString,
+	"user":                       nil,
+	"users":                      ccList(ccUser),
+	"write_files":                ccList(ccWriteFile),
+	"yum_repos":                  ccMapping,
+}
+
+var cloudConfigWriteFileEncodings = map[string]bool{
+	"b64": true, "base64": true,
+	"gz": true, "gzip": true,
+	"gz+b64": true, "gz+base64": true, "gzip+b64": true, "gzip+base64": true,
+	"text/plain": true,
+}
+
+func ccString(v cty.Value, path cty.Path) []error {
+	if v.IsNull() || v.Type() != cty.String {
+		return []error{path.NewErrorf("must be a string, not %s", cloudConfigTypeName(v))}
+	}
+	return nil
+}
+
+func ccBool(v cty.Value, path cty.Path) []error {
+	if v.IsNull() || v.Type() != cty.Bool {
+		return []error{path.NewErrorf("must be a boolean, not %s", cloudConfigTypeName(v))}
+	}
+	return nil
+}
+
+func ccMapping(v cty.Value, path cty.Path) []error {
+	if v.IsNull() || !v.Type().IsObjectType() {
+		return []error{path.NewErrorf("must be a mapping, not %s", cloudConfigTypeName(v))}
+	}
+	return nil
+}
+
+func ccStringOrStrings(v cty.Value, path cty.Path) []error {
+	if !v.IsNull() && v.Type() == cty.String {
+		return nil
+	}
+	if !v.IsNull() && v.Type().IsTupleType() {
+		return ccList(ccString)(v, path)
+	}
+	return []error{path.NewErrorf("must be a string or a list of strings, not %s", cloudConfigTypeName(v))}
+}
+
+// ccList returns a check that the value is a sequence whose elements each
+// pass the given check, if it is non-nil.
+func ccList(elem cloudConfigCheck) cloudConfigCheck {
+	return func(v cty.Value, path cty.Path) []error {
+		if v.IsNull() || !v.Type().IsTupleType() {
+			return []error{path.NewErrorf("must be a list, not %s", cloudConfigTypeName(v))}
+		}
+		if elem == nil {
+			return nil
+		}
+		var errs []error
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			errs = append(errs, elem(ev, path.Index(k))...)
+		}
+		return errs
+	}
+}
+
+// ccCommands checks the value of runcmd or bootcmd, where each command is
+// either a string to run with a shell or a list of arguments.
+func ccCommands(v cty.Value, path cty.Path) []error {
+	return ccList(ccStringOrStrings)(v, path)
+}
+
+func ccUser(v cty.Value, path cty.Path) []error {
+	if !v.IsNull() && v.Type() == cty.String {
+		// A string is either "default" or a username.
+		return nil
+	}
+	if errs := ccMapping(v, path); errs != nil {
+		return []error{path.NewErrorf("must be a string or a mapping, not %s", cloudConfigTypeName(v))}
+	}
+	var errs []error
+	if !v.Type().HasAttribute("name") {
+		errs = append(errs, path.NewErrorf("the \"name\" key is required"))
+	} else {
+		errs = append(errs, ccString(v.GetAttr("name"), path.GetAttr("name"))...)
+	}
+	for _, name := range []string{"gecos", "homedir", "primary_group", "shell", "passwd", "hashed_passwd", "lock_passwd"} {
+		if !v.Type().HasAttribute(name) {
+			continue
+		}
+		if name == "lock_passwd" {
+			errs = append(errs, ccBool(v.GetAttr(name), path.GetAttr(name))...)
+		} else {
+			errs = append(errs, ccString(v.GetAttr(name), path.GetAttr(name))...)
+		}
+	}
+	if v.Type().HasAttribute("ssh_authorized_keys") {
+		errs = append(errs, ccList(ccString)(v.GetAttr("ssh_authorized_keys"), path.GetAttr("ssh_authorized_keys"))...)
+	}
+	return errs
+}
+
+func ccWriteFile(v cty.Value, path cty.Path) []error {
+	if errs := ccMapping(v, path); errs != nil {
+		return errs
+	}
+	var errs []error
+	if !v.Type().HasAttribute("path") {
+		errs = append(errs, path.NewErrorf("the \"path\" key is required"))
+	} else {
+		errs = append(errs, ccString(v.GetAttr("path"), path.GetAttr("path"))...)
+	}
+	for _, name := range []string{"content", "owner", "permissions"} {
+		if v.Type().HasAttribute(name) {
+			errs = append(errs, ccString(v.GetAttr(name), path.GetAttr(name))...)
+		}
+	}
+	for _, name := range []string{"append", "defer"} {
+		if v.Type().HasAttribute(name) {
+			errs = append(errs, ccBool(v.GetAttr(name), path.GetAttr(name))...)
+		}
+	}
+	if v.Type().HasAttribute("encoding") {
+		encPath := path.GetAttr("encoding")
+		enc := v.GetAttr("encoding")
+		if encErrs := ccString(enc, encPath); encErrs != nil {
+			errs = append(errs, encErrs...)
+		} else if !cloudConfigWriteFileEncodings[strings.ToLower(enc.AsString())] {
+			errs = append(errs, encPath.NewErrorf("unsupported encoding %q; must be one of b64, gzip, gz+b64, or text/plain", enc.AsString()))
+		}
+	}
+	if v.Type().HasAttribute("permissions") {
+		perm := v.GetAttr("permissions")
+		if !perm.IsNull() && perm.Type() == cty.String && !isOctalPermissions(perm.AsString()) {
+			errs = append(errs, path.GetAttr("permissions").NewErrorf("must be an octal mode string like \"0644\", not %q", perm.AsString()))
+		}
+	}
+	return errs
+}
+
+func isOctalPermissions(s string) bool {
+	s = strings.TrimPrefix(s, "0o")
+	if len(s) < 3 || len(s) > 4 {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '7' {
+			return false
+		}
+	}
+	return true
+}
+
+// cloudConfigTypeName returns a YAML-oriented description of the type of the
+// given value decoded from a cloud-config document, for error messages.
+func cloudConfigTypeName(v cty.Value) string {
+	switch {
+	case v.IsNull():
+		return "null"
+	case v.Type() == cty.String:
+		return "a string"
+	case v.Type() == cty.Bool:
+		return "a boolean"
+	case v.Type() == cty.Number:
+		return "a number"
+	case v.Type().IsTupleType():
+		return "a list"
+	case v.Type().IsObjectType():
+		return "a mapping"
+	default:
+		return v.Type().FriendlyName()
+	}
+}
+
+// cloudConfigErrorLocation describes where a cloud-config validation error
+// occurred, combining the part location with the path inside the document.
+func cloudConfigErrorLocation(part userDataPart, err error) (string, string) {
+	if pathErr, ok := err.(cty.PathError); ok && len(pathErr.Path) > 0 {
+		return fmt.Sprintf("%s, at %s", part.Location, strings.TrimPrefix(tfsdk.FormatPath(pathErr.Path), ".")), pathErr.Error()
+	}
+	return part.Location, err.Error()
+}
@@ -0,0 +1,388 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+// newDataResourceType is a wrapper around tfsdk.NewDataResourceType that
+// arranges for the provider-level settings in Client that affect how test
+// results are reported to apply to the given data resource type.
+//
+// def.ReadFn must have the signature shared by all of the data sources in
+// this package:
+//
+//	func (ctx context.Context, client *Client, obj *T) (*T, tfsdk.Diagnostics)
+//
+// Like tfsdk.NewDataResourceType, this panics if def is invalid, so that
+// mistakes are caught when the provider starts rather than when Terraform
+// first reads the data source. The panic value is an invalidDefinitionError
+// describing all of the problems found.
+func newDataResourceType(typeName string, def *tfsdk.ResourceTypeDef, opts ...dataResourceTypeOption) tfsdk.DataResourceType {
+	if errs := checkDataResourceTypeDef(def); len(errs) != 0 {
+		panic(invalidDefinitionError{TypeName: typeName, Problems: errs})
+	}
+	var hasOwnTimeout bool
+	for _, opt := range opts {
+		switch opt {
+		case ownTimeout:
+			hasOwnTimeout = true
+		}
+	}
+	def.ReadFn = wrapReadFn(typeName, def.ReadFn, hasOwnTimeout)
+	return dataResourceType{
+		DataResourceType: tfsdk.NewDataResourceType(def),
+		schema:           def.ConfigSchema,
+	}
+}
+
+// dataResourceType is the SDK's data resource type along with its schema,
+// which the SDK keeps to itself, so that the provider can describe its own
+// data sources in ProviderSchemaJSON.
+type dataResourceType struct {
+	tfsdk.DataResourceType
+	schema *tfschema.BlockType
+}
+
+// dataResourceTypeOption is an optional argument to newDataResourceType.
+type dataResourceTypeOption int
+
+const (
+	// ownTimeout marks a data source whose own "timeout" argument bounds
+	// its whole read. The provider's default_timeout then serves only as
+	// the default for that argument, via Client.timeout, rather than also
+	// bounding the read.
+	ownTimeout dataResourceTypeOption = iota
+)
+
+// invalidDefinitionError is the panic value of newDataResourceType when
+// given an invalid definition.
+type invalidDefinitionError struct {
+	TypeName string
+	Problems []error
+}
+
+func (e invalidDefinitionError) Error() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "invalid definition for %s:", e.TypeName)
+	for _, err := range e.Problems {
+		fmt.Fprintf(&buf, "\n  - %s", err)
+	}
+	return buf.String()
+}
+
+// CheckDataResourceTypes constructs each of the provider's data resource
+// types and returns all of the problems found in their definitions, in
+// order of data source type name and then attribute name. The provider
+// itself panics on the first invalid definition, so this is for checking
+// the provider's implementation as a whole.
+func CheckDataResourceTypes() []error {
+	names := make([]string, 0, len(dataResourceTypes))
+	for name := range dataResourceTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		errs = append(errs, checkDataResourceType(name, dataResourceTypes[name])...)
+	}
+	return errs
+}
+
+// checkDataResourceType calls the given constructor for the named data
+// resource type and returns the problems it panicked with, if any.
+func checkDataResourceType(name string, fn func() tfsdk.DataResourceType) (errs []error) {
+	defer func() {
+		switch r := recover().(type) {
+		case nil:
+		case invalidDefinitionError:
+			for _, err := range r.Problems {
+				errs = append(errs, fmt.Errorf("%s: %s", name, err))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%s: %v", name, r))
+		}
+	}()
+	fn()
+	return nil
+}
+
+// checkDataResourceTypeDef returns errors describing any problems with the
+// given definition: a ReadFn without the signature that newDataResourceType
+// requires, an object type that lacks fields for any of the arguments in
+// the schema, or attributes with invalid flags or ValidateFns.
+func checkDataResourceTypeDef(def *tfsdk.ResourceTypeDef) []error {
+	ctxType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	clientType := reflect.TypeOf((*Client)(nil))
+	diagsType := reflect.TypeOf(tfsdk.Diagnostics(nil))
+
+	var errs []error
+	if def.ConfigSchema != nil {
+		errs = append(errs, checkSchema(def.ConfigSchema, "")...)
+	}
+
+	ft := reflect.TypeOf(def.ReadFn)
+	switch {
+	case ft == nil || ft.Kind() != reflect.Func:
+		return append(errs, fmt.Errorf("ReadFn is %T, not a function", def.ReadFn))
+	case ft.NumIn() != 3 || ft.In(0) != ctxType || ft.In(1) != clientType:
+		return append(errs, fmt.Errorf("ReadFn must take a context.Context, a *Client, and a pointer to a struct"))
+	case ft.In(2).Kind() != reflect.Ptr || ft.In(2).Elem().Kind() != reflect.Struct:
+		return append(errs, fmt.Errorf("ReadFn's third argument must be a pointer to a struct, not %s", ft.In(2)))
+	case ft.NumOut() != 2 || ft.Out(0) != ft.In(2) || ft.Out(1) != diagsType:
+		return append(errs, fmt.Errorf("ReadFn must return %s and tfsdk.Diagnostics", ft.In(2)))
+	}
+	if def.ConfigSchema != nil {
+		errs = append(errs, checkObjectFields(def.ConfigSchema, ft.In(2).Elem(), "")...)
+	}
+	return errs
+}
+
+// checkSchema returns errors describing any attributes in the given schema,
+// or in its nested blocks, that have an invalid combination of the
+// Required, Optional, and Computed flags, or a ValidateFn that can't accept
+// values of the attribute's type. prefix is prepended to the names in any
+// error message, to identify nested blocks.
+func checkSchema(schema *tfschema.BlockType, prefix string) []error {
+	diagsType := reflect.TypeOf(tfsdk.Diagnostics(nil))
+
+	var errs []error
+	for _, name := range sortedKeys(schema.Attributes) {
+		attr := schema.Attributes[name]
+		switch {
+		case attr.Type == cty.NilType:
+			errs = append(errs, fmt.Errorf("the %q attribute has no type", prefix+name))
+		case attr.Required && (attr.Optional || attr.Computed):
+			errs = append(errs, fmt.Errorf("the %q attribute is Required, so it can't also be Optional or Computed", prefix+name))
+		case !attr.Required && !attr.Optional && !attr.Computed:
+			errs = append(errs, fmt.Errorf("the %q attribute must be Required, Optional, or Computed", prefix+name))
+		case attr.Computed && !attr.Optional && attr.ValidateFn != nil:
+			errs = append(errs, fmt.Errorf("the %q attribute has a ValidateFn, but it is only Computed, so the ValidateFn is never called", prefix+name))
+		}
+		if attr.ValidateFn == nil || attr.Type == cty.NilType {
+			continue
+		}
+
+		ft := reflect.TypeOf(attr.ValidateFn)
+		if ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.NumOut() != 1 || ft.Out(0) != diagsType {
+			errs = append(errs, fmt.Errorf("the %q attribute's ValidateFn is %s, not a function of one argument that returns tfsdk.Diagnostics", prefix+name, ft))
+			continue
+		}
+		if !goTypeAccepts(ft.In(0), attr.Type) {
+			errs = append(errs, fmt.Errorf("the %q attribute's ValidateFn takes %s, which can't represent values of type %s", prefix+name, ft.In(0), attr.Type.FriendlyName()))
+		}
+	}
+	for _, name := range sortedKeys(schema.NestedBlockTypes) {
+		errs = append(errs, checkSchema(&schema.NestedBlockTypes[name].Content, prefix+name+".")...)
+	}
+	return errs
+}
+
+// goTypeAccepts returns true if package gocty can convert values of the given
+// cty type to the given Go type, as the SDK does for the argument of a
+// ValidateFn.
+//
+// It tries converting an example value, so it can't tell whether a Go type
+// would accept all values of the cty type, but it catches ValidateFns that
+// were written for the wrong type altogether. Attributes of dynamic type
+// have no representative value, so they require a cty.Value argument.
+func goTypeAccepts(t reflect.Type, ty cty.Type) bool {
+	if t == reflect.TypeOf(cty.Value{}) {
+		return true
+	}
+	v, ok := exampleValue(ty)
+	if !ok {
+		return false
+	}
+	return gocty.FromCtyValue(v, reflect.New(t).Interface()) == nil
+}
+
+// exampleValue returns a known, non-empty value of the given type, or false
+// if the type is or contains cty.DynamicPseudoType.
+func exampleValue(ty cty.Type) (cty.Value, bool) {
+	switch {
+	case ty == cty.String:
+		return cty.StringVal("a"), true
+	case ty == cty.Number:
+		return cty.NumberIntVal(1), true
+	case ty == cty.Bool:
+		return cty.True, true
+	case ty.IsListType():
+		ev, ok := exampleValue(ty.ElementType())
+		return cty.ListVal([]cty.Value{ev}), ok
+	case ty.IsSetType():
+		ev, ok := exampleValue(ty.ElementType())
+		return cty.SetVal([]cty.Value{ev}), ok
+	case ty.IsMapType():
+		ev, ok := exampleValue(ty.ElementType())
+		return cty.MapVal(map[string]cty.Value{"a": ev}), ok
+	case ty.IsObjectType():
+		attrs := map[string]cty.Value{}
+		for name, aty := range ty.AttributeTypes() {
+			av, ok := exampleValue(aty)
+			if !ok {
+				return cty.NilVal, false
+			}
+			attrs[name] = av
+		}
+		return cty.ObjectVal(attrs), true
+	case ty.IsTupleType():
+		var elems []cty.Value
+		for _, ety := range ty.TupleElementTypes() {
+			ev, ok := exampleValue(ety)
+			if !ok {
+				return cty.NilVal, false
+			}
+			elems = append(elems, ev)
+		}
+		return cty.TupleVal(elems), true
+	default:
+		return cty.NilVal, false
+	}
+}
+
+// checkObjectFields returns errors for each of the given schema's attributes
+// and nested blocks that the given struct type, which represents objects
+// conforming to the schema, lacks a field with a "cty" tag for. prefix is
+// prepended to the names in any error message, to identify nested blocks.
+func checkObjectFields(schema *tfschema.BlockType, t reflect.Type, prefix string) []error {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if name := f.Tag.Get("cty"); name != "" {
+			fields[name] = f.Type
+		}
+	}
+	var errs []error
+	for _, name := range sortedKeys(schema.Attributes) {
+		if _, ok := fields[name]; !ok {
+			errs = append(errs, fmt.Errorf("%s has no field for the %q attribute", t, prefix+name))
+		}
+	}
+	for _, name := range sortedKeys(schema.NestedBlockTypes) {
+		ft, ok := fields[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s has no field for the %q block", t, prefix+name))
+			continue
+		}
+		// Blocks might be represented by a slice, map, or pointer of a
+		// struct type, or by something else entirely such as cty.Value, in
+		// which case there's nothing more we can check.
+		for ft.Kind() == reflect.Slice || ft.Kind() == reflect.Map || ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct || ft == reflect.TypeOf(cty.Value{}) {
+			continue
+		}
+		errs = append(errs, checkObjectFields(&schema.NestedBlockTypes[name].Content, ft, prefix+name+".")...)
+	}
+	return errs
+}
+
+// sortedKeys returns the keys of the given map, which must have string keys,
+// in lexical order.
+func sortedKeys(m interface{}) []string {
+	keys := reflect.ValueOf(m).MapKeys()
+	ret := make([]string, len(keys))
+	for i, k := range keys {
+		ret[i] = k.String()
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// wrapReadFn returns a function of the same type as the given ReadFn that
+// consults the client before and after calling it. Unless hasOwnTimeout is
+// set, the read is cancelled after the provider's default_timeout.
+func wrapReadFn(typeName string, fn interface{}, hasOwnTimeout bool) interface{} {
+	fv := reflect.ValueOf(fn)
+	return reflect.MakeFunc(fv.Type(), func(args []reflect.Value) []reflect.Value {
+		client := args[1].Interface().(*Client)
+		result := testResult{
+			DataSource: typeName,
+			Subject:    objSubject(args[2]),
+			Time:       time.Now(),
+		}
+		if skip, diags := client.beforeRead(); skip {
+			return []reflect.Value{args[2], reflect.ValueOf(client.afterRead(result, diags))}
+		}
+
+		var timedOut func() bool
+		if client.defaultTimeout != 0 && !hasOwnTimeout {
+			ctx, cancel := context.WithTimeout(args[0].Interface().(context.Context), client.defaultTimeout)
+			defer cancel()
+			args = append([]reflect.Value{reflect.ValueOf(ctx)}, args[1:]...)
+			timedOut = func() bool { return ctx.Err() == context.DeadlineExceeded }
+		}
+
+		results := fv.Call(args)
+		result.DurationMS = durationMillis(time.Since(result.Time))
+		result.Assertions = objAssertionResults(results[0])
+		diags := results[1].Interface().(tfsdk.Diagnostics)
+		if timedOut != nil && timedOut() && diags.HasErrors() && !hasSummary(diags, "Data source timed out") {
+			diags = diags.Append(tfsdk.Diagnostic{
+				Severity: tfsdk.Error,
+				Summary:  "Data source timed out",
+				Detail:   fmt.Sprintf("The data source did not finish within the default_timeout of %s set in the provider configuration, so any operations still in progress were cancelled.", client.defaultTimeout),
+			})
+		}
+		diags = client.afterRead(result, diags)
+		return []reflect.Value{results[0], reflect.ValueOf(diags)}
+	}).Interface()
+}
+
+// objSubject returns the value of the "subject" argument of the given
+// data source object, or an empty string if it is not set or the data
+// source has no such argument.
+func objSubject(obj reflect.Value) string {
+	if obj.IsNil() {
+		return ""
+	}
+	f := obj.Elem().FieldByName("Subject")
+	if !f.IsValid() || f.Type() != reflect.TypeOf((*string)(nil)) || f.IsNil() {
+		return ""
+	}
+	return f.Elem().String()
+}
+
+// objAssertionResults returns the outcomes of the individual assertions
+// recorded in the AssertionResults field of the given data source object,
+// if it has such a field.
+func objAssertionResults(obj reflect.Value) []assertionResult {
+	if obj.IsNil() {
+		return nil
+	}
+	f := obj.Elem().FieldByName("AssertionResults")
+	if !f.IsValid() || f.Type() != reflect.TypeOf([]assertionResult(nil)) {
+		return nil
+	}
+	return f.Interface().([]assertionResult)
+}
+
+// hasSummary returns true if any of the given diagnostics has the given
+// summary.
+func hasSummary(diags tfsdk.Diagnostics, summary string) bool {
+	for _, diag := range diags {
+		if diag.Summary == summary {
+			return true
+		}
+	}
+	return false
+}
+
+// isTestFailure returns true if the given diagnostic reports a failed
+// assertion, as opposed to a problem with the configuration or an error
+// while collecting the values to test.
+func isTestFailure(diag tfsdk.Diagnostic) bool {
+	return diag.Severity == tfsdk.Error && diag.Summary == "Test failure"
+}
@@ -0,0 +1,386 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestCheckDataResourceTypeDef(t *testing.T) {
+	type item struct {
+		Name string `cty:"name"`
+	}
+	type obj struct {
+		Subject *string `cty:"subject"`
+		Items   []item  `cty:"item"`
+	}
+	schema := &tfschema.BlockType{
+		Attributes: map[string]*tfschema.Attribute{
+			"subject": {Type: cty.String, Optional: true},
+		},
+		NestedBlockTypes: map[string]*tfschema.NestedBlockType{
+			"item": {
+				Nesting: tfschema.NestingList,
+				Content: tfschema.BlockType{
+					Attributes: map[string]*tfschema.Attribute{
+						"name":  {Type: cty.String, Required: true},
+						"value": {Type: cty.String, Optional: true},
+					},
+				},
+			},
+		},
+	}
+	read := func(ctx context.Context, client *Client, o *obj) (*obj, tfsdk.Diagnostics) {
+		return o, nil
+	}
+
+	tests := map[string]struct {
+		Def      *tfsdk.ResourceTypeDef
+		WantErrs []string
+	}{
+		"valid": {
+			&tfsdk.ResourceTypeDef{ReadFn: read},
+			nil,
+		},
+		"wrong signature": {
+			&tfsdk.ResourceTypeDef{
+				ReadFn: func(ctx context.Context, client interface{}, o *obj) (*obj, tfsdk.Diagnostics) {
+					return o, nil
+				},
+			},
+			[]string{"ReadFn must take a context.Context, a *Client, and a pointer to a struct"},
+		},
+		"missing nested field": {
+			&tfsdk.ResourceTypeDef{ConfigSchema: schema, ReadFn: read},
+			[]string{`testing.item has no field for the "item.value" attribute`},
+		},
+		"several problems": {
+			&tfsdk.ResourceTypeDef{
+				ConfigSchema: &tfschema.BlockType{
+					Attributes: map[string]*tfschema.Attribute{
+						"subject": {
+							Type:       cty.String,
+							Optional:   true,
+							ValidateFn: func(v int) tfsdk.Diagnostics { return nil },
+						},
+						"b": {Type: cty.String},
+						"a": {Type: cty.String, Required: true, Computed: true},
+						"c": {
+							Type:       cty.List(cty.String),
+							Optional:   true,
+							ValidateFn: func(v []string) error { return nil },
+						},
+						"d": {
+							Type:       cty.Map(cty.String),
+							Computed:   true,
+							ValidateFn: func(v map[string]string) tfsdk.Diagnostics { return nil },
+						},
+					},
+				},
+				ReadFn: read,
+			},
+			[]string{
+				`the "a" attribute is Required, so it can't also be Optional or Computed`,
+				`the "b" attribute must be Required, Optional, or Computed`,
+				`the "c" attribute's ValidateFn is func([]string) error, not a function of one argument that returns tfsdk.Diagnostics`,
+				`the "d" attribute has a ValidateFn, but it is only Computed, so the ValidateFn is never called`,
+				`the "subject" attribute's ValidateFn takes int, which can't represent values of type string`,
+				`testing.obj has no field for the "a" attribute`,
+				`testing.obj has no field for the "b" attribute`,
+				`testing.obj has no field for the "c" attribute`,
+				`testing.obj has no field for the "d" attribute`,
+			},
+		},
+		"dynamic ValidateFn": {
+			&tfsdk.ResourceTypeDef{
+				ConfigSchema: &tfschema.BlockType{
+					Attributes: map[string]*tfschema.Attribute{
+						"subject": {
+							Type:       cty.DynamicPseudoType,
+							Optional:   true,
+							ValidateFn: func(v string) tfsdk.Diagnostics { return nil },
+						},
+					},
+				},
+				ReadFn: read,
+			},
+			[]string{`the "subject" attribute's ValidateFn takes string, which can't represent values of type dynamic`},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			for _, err := range checkDataResourceTypeDef(test.Def) {
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, test.WantErrs) {
+				t.Errorf("wrong errors\ngot:  %s\nwant: %s", strings.Join(got, "\n      "), strings.Join(test.WantErrs, "\n      "))
+			}
+		})
+	}
+}
+
+func TestCheckDataResourceTypes(t *testing.T) {
+	for _, err := range CheckDataResourceTypes() {
+		t.Error(err)
+	}
+}
+
+func TestWrapReadFnFailFast(t *testing.T) {
+	type obj struct{}
+	calls := 0
+	read := wrapReadFn("testing_test", func(ctx context.Context, client *Client, o *obj) (*obj, tfsdk.Diagnostics) {
+		calls++
+		var diags tfsdk.Diagnostics
+		for i := 0; i < 2; i++ {
+			diags = diags.Append(tfsdk.Diagnostic{
+				Severity: tfsdk.Error,
+				Summary:  "Test failure",
+			})
+		}
+		return o, diags
+	}, false).(func(context.Context, *Client, *obj) (*obj, tfsdk.Diagnostics))
+
+	t.Run("disabled", func(t *testing.T) {
+		calls = 0
+		client := &Client{}
+		for i := 0; i < 2; i++ {
+			_, diags := read(context.Background(), client, &obj{})
+			if got, want := len(diags), 2; got != want {
+				t.Errorf("wrong number of diagnostics %d; want %d", got, want)
+			}
+		}
+		if got, want := calls, 2; got != want {
+			t.Errorf("wrong number of calls %d; want %d", got, want)
+		}
+	})
+	t.Run("enabled", func(t *testing.T) {
+		calls = 0
+		client := &Client{failFast: true}
+		_, diags := read(context.Background(), client, &obj{})
+		if got, want := len(diags), 1; got != want {
+			t.Errorf("wrong number of diagnostics from first read %d; want %d", got, want)
+		}
+		_, diags = read(context.Background(), client, &obj{})
+		if got, want := len(diags), 1; got != want {
+			t.Fatalf("wrong number of diagnostics from second read %d; want %d", got, want)
+		}
+		if got, want := diags[0].Summary, "Test skipped"; got != want {
+			t.Errorf("wrong summary %q; want %q", got, want)
+		}
+		if got, want := diags[0].Severity, tfsdk.Warning; got != want {
+			t.Errorf("wrong severity %v; want %v", got, want)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("wrong number of calls %d; want %d", got, want)
+		}
+	})
+}
+
+func TestClientFailedFast(t *testing.T) {
+	failure := tfsdk.Diagnostics{{Severity: tfsdk.Error, Summary: "Test failure"}}
+	warning := tfsdk.Diagnostics{{Severity: tfsdk.Warning, Summary: "Test passed unexpectedly"}}
+	tests := map[string]struct {
+		Client *Client
+		Diags  tfsdk.Diagnostics
+		Want   bool
+	}{
+		"disabled":   {&Client{}, failure, false},
+		"no failure": {&Client{failFast: true}, warning, false},
+		"failure":    {&Client{failFast: true}, failure, true},
+		"dry run":    {&Client{failFast: true, dryRun: true}, failure, false},
+		"downgraded": {
+			&Client{failFast: true, severityOverrides: map[string]string{"Test failure": "warning"}},
+			failure,
+			false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.Client.failedFast(test.Diags); got != test.Want {
+				t.Errorf("wrong result %t; want %t", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestProviderFailFast(t *testing.T) {
+	wd := testHelper.RequireNewWorkingDir(t)
+	defer wd.Close()
+
+	wd.RequireSetConfig(t, `
+provider "testing" {
+  fail_fast = true
+}
+
+data "testing_assertions" "test" {
+  check "a" {
+    expect = false
+  }
+  check "b" {
+    expect = false
+  }
+}
+
+data "testing_assertions" "later" {
+  depends_on = [data.testing_assertions.test]
+
+  check "a" {
+    expect = false
+  }
+}
+`)
+
+	wd.RequireInit(t)
+	err := wd.Apply()
+	if err == nil {
+		t.Fatal("succeeded; want error")
+	}
+	if got, want := strings.Count(err.Error(), "Test failure"), 1; got != want {
+		t.Errorf("wrong number of test failures reported %d; want %d\n%s", got, want, err)
+	}
+	if got, want := strings.Count(err.Error(), "Error: "), 1; got != want {
+		t.Errorf("wrong number of errors reported %d; want %d\n%s", got, want, err)
+	}
+}
+
+func TestProviderSubjectPrefixAndTags(t *testing.T) {
+	wd := testHelper.RequireNewWorkingDir(t)
+	defer wd.Close()
+
+	wd.RequireSetConfig(t, `
+provider "testing" {
+  subject_prefix = "network module"
+  tags = {
+    suite = "network"
+    env   = "ci"
+  }
+}
+
+data "testing_assertions" "test" {
+  check "a" {
+    statement = "works"
+    expect    = false
+  }
+}
+`)
+
+	wd.RequireInit(t)
+	err := wd.Apply()
+	if err == nil {
+		t.Fatal("succeeded; want error")
+	}
+	for _, want := range []string{
+		"network module: Assertion failed: works.",
+		"Tags: env=ci, suite=network",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not contain %q\n%s", want, err)
+		}
+	}
+}
+
+func TestProviderSeverity(t *testing.T) {
+	tests := map[string]struct {
+		Provider string
+		WantErr  bool
+	}{
+		"default": {
+			``,
+			false,
+		},
+		"warnings_as_errors": {
+			`warnings_as_errors = true`,
+			true,
+		},
+		"override to warning": {
+			`warnings_as_errors = true
+  severity_overrides = {
+    "Test passed unexpectedly" = "warning"
+  }`,
+			false,
+		},
+		"override to error": {
+			`severity_overrides = {
+    "Test passed unexpectedly" = "error"
+  }`,
+			true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			wd := testHelper.RequireNewWorkingDir(t)
+			defer wd.Close()
+
+			wd.RequireSetConfig(t, fmt.Sprintf(`
+provider "testing" {
+  %s
+}
+
+data "testing_tap" "test" {
+  program = ["echo", "ok 1 - bonus # TODO not implemented yet"]
+}
+`, test.Provider))
+
+			wd.RequireInit(t)
+			err := wd.Apply()
+			if test.WantErr && err == nil {
+				t.Error("succeeded; want error")
+			}
+			if !test.WantErr && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestProviderDefaultTimeout(t *testing.T) {
+	wd := testHelper.RequireNewWorkingDir(t)
+	defer wd.Close()
+
+	wd.RequireSetConfig(t, `
+provider "testing" {
+  default_timeout = "1s"
+}
+
+data "testing_tap" "test" {
+  program = ["sleep", "10"]
+}
+`)
+
+	wd.RequireInit(t)
+	err := wd.Apply()
+	if err == nil {
+		t.Fatal("succeeded; want error")
+	}
+	if want := "Data source timed out"; !strings.Contains(err.Error(), want) {
+		t.Errorf("error does not contain %q\n%s", want, err)
+	}
+}
+
+func TestWrapReadFnDefaultTimeout(t *testing.T) {
+	type obj struct {
+		Timeout *string `cty:"timeout"`
+	}
+	var hasDeadline bool
+	fn := func(ctx context.Context, client *Client, o *obj) (*obj, tfsdk.Diagnostics) {
+		_, hasDeadline = ctx.Deadline()
+		return o, nil
+	}
+	client := &Client{defaultTimeout: time.Minute}
+
+	for _, own := range []bool{false, true} {
+		read := wrapReadFn("testing_test", fn, own).(func(context.Context, *Client, *obj) (*obj, tfsdk.Diagnostics))
+		read(context.Background(), client, &obj{})
+		if got, want := hasDeadline, !own; got != want {
+			t.Errorf("with own timeout %t, read has deadline %t; want %t", own, got, want)
+		}
+	}
+}
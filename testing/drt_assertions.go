@@ -15,9 +15,15 @@ type assertionsDRT struct {
 
 	Checks cty.Value `cty:"check"`
 	Equals cty.Value `cty:"equal"`
+	Exprs  cty.Value `cty:"expr"`
+
+	// AssertionResults records the outcome of each assertion for the
+	// provider's report. It isn't part of the schema, so it has no tag.
+	AssertionResults []assertionResult
 }
 
 type assertionsDRTEqual struct {
+	ID        *string `cty:"id"`
 	Statement *string `cty:"statement"`
 
 	Got  cty.Value `cty:"got"`
@@ -25,13 +31,23 @@ type assertionsDRTEqual struct {
 }
 
 type assertionsDRTCheck struct {
+	ID        *string `cty:"id"`
 	Statement *string `cty:"statement"`
 
 	Pass bool `cty:"expect"`
 }
 
+type assertionsDRTExpr struct {
+	ID        *string `cty:"id"`
+	Statement *string `cty:"statement"`
+
+	Condition string    `cty:"condition"`
+	Got       cty.Value `cty:"got"`
+	Vars      cty.Value `cty:"vars"`
+}
+
 func assertionsDataResourceType() tfsdk.DataResourceType {
-	return tfsdk.NewDataResourceType(&tfsdk.ResourceTypeDef{
+	return newDataResourceType("testing_assertions", &tfsdk.ResourceTypeDef{
 		ConfigSchema: &tfschema.BlockType{
 			Attributes: map[string]*tfschema.Attribute{
 				"subject": {Type: cty.String, Optional: true},
@@ -41,6 +57,7 @@ func assertionsDataResourceType() tfsdk.DataResourceType {
 					Nesting: tfschema.NestingMap,
 					Content: tfschema.BlockType{
 						Attributes: map[string]*tfschema.Attribute{
+							"id":        {Type: cty.String, Optional: true},
 							"statement": {Type: cty.String, Optional: true},
 
 							"expect": {Type: cty.Bool, Required: true},
@@ -51,6 +68,7 @@ func assertionsDataResourceType() tfsdk.DataResourceType {
 					Nesting: tfschema.NestingMap,
 					Content: tfschema.BlockType{
 						Attributes: map[string]*tfschema.Attribute{
+							"id":        {Type: cty.String, Optional: true},
 							"statement": {Type: cty.String, Optional: true},
 
 							"want": {Type: cty.DynamicPseudoType, Required: true},
@@ -58,6 +76,23 @@ func assertionsDataResourceType() tfsdk.DataResourceType {
 						},
 					},
 				},
+				"expr": {
+					Nesting: tfschema.NestingMap,
+					Content: tfschema.BlockType{
+						Attributes: map[string]*tfschema.Attribute{
+							"id":        {Type: cty.String, Optional: true},
+							"statement": {Type: cty.String, Optional: true},
+
+							"condition": {
+								Type:       cty.String,
+								Required:   true,
+								ValidateFn: validateAssertionExpr,
+							},
+							"got":  {Type: cty.DynamicPseudoType, Optional: true},
+							"vars": {Type: cty.DynamicPseudoType, Optional: true},
+						},
+					},
+				},
 			},
 		},
 
@@ -68,8 +103,12 @@ func assertionsDataResourceType() tfsdk.DataResourceType {
 			if obj.Subject != nil {
 				subject = *obj.Subject
 			}
+			ids := make(map[string]bool)
 
 			for it := obj.Checks.ElementIterator(); it.Next(); {
+				if client.failedFast(diags) {
+					break
+				}
 				k, v := it.Element()
 				var chk assertionsDRTCheck
 				err := gocty.FromCtyValue(v, &chk)
@@ -83,9 +122,12 @@ func assertionsDataResourceType() tfsdk.DataResourceType {
 					continue
 				}
 
+				diags = diags.Append(checkAssertionID(chk.ID, ids, cty.Path(nil).GetAttr("check").Index(k)))
 				if chk.Pass {
+					obj.recordAssertion("check", k, chk.ID, testResultPass)
 					continue
 				}
+				obj.recordAssertion("check", k, chk.ID, testResultFail)
 
 				statement := ""
 				if chk.Statement != nil {
@@ -96,22 +138,18 @@ func assertionsDataResourceType() tfsdk.DataResourceType {
 					}
 				}
 
-				msg := "Assertion failed"
-				if statement != "" {
-					msg = fmt.Sprintf("%s: %s.", msg, statement)
-				} else {
-					msg = msg + "."
-				}
-
 				diags = diags.Append(tfsdk.Diagnostic{
 					Severity: tfsdk.Error,
 					Summary:  "Test failure",
-					Detail:   msg,
+					Detail:   assertionFailureHeading(chk.ID, statement),
 					Path:     cty.Path(nil).GetAttr("check").Index(k).GetAttr("expect"),
 				})
 			}
 
 			for it := obj.Equals.ElementIterator(); it.Next(); {
+				if client.failedFast(diags) {
+					break
+				}
 				k, v := it.Element()
 				var eq assertionsDRTEqual
 				err := gocty.FromCtyValue(v, &eq)
@@ -125,10 +163,13 @@ func assertionsDataResourceType() tfsdk.DataResourceType {
 					continue
 				}
 
+				diags = diags.Append(checkAssertionID(eq.ID, ids, cty.Path(nil).GetAttr("equal").Index(k)))
 				if eq.Got.RawEquals(eq.Want) {
 					// Assertion passes!
+					obj.recordAssertion("equal", k, eq.ID, testResultPass)
 					continue
 				}
+				obj.recordAssertion("equal", k, eq.ID, testResultFail)
 
 				statement := ""
 				if eq.Statement != nil {
@@ -139,21 +180,12 @@ func assertionsDataResourceType() tfsdk.DataResourceType {
 					}
 				}
 
-				var msg string
-				if statement != "" {
-					msg = fmt.Sprintf(
-						"Assertion failed: %s.\n  Want: %s\n  Got:  %s",
-						statement,
-						formatValue(eq.Want, 2),
-						formatValue(eq.Got, 2),
-					)
-				} else {
-					msg = fmt.Sprintf(
-						"Assertion failed.\n  Want: %s\n  Got:  %s",
-						formatValue(eq.Want, 2),
-						formatValue(eq.Got, 2),
-					)
-				}
+				msg := fmt.Sprintf(
+					"%s\n  Want: %s\n  Got:  %s",
+					assertionFailureHeading(eq.ID, statement),
+					formatValue(eq.Want, 2),
+					formatValue(eq.Got, 2),
+				)
 
 				diags = diags.Append(tfsdk.Diagnostic{
 					Severity: tfsdk.Error,
@@ -163,7 +195,110 @@ func assertionsDataResourceType() tfsdk.DataResourceType {
 				})
 			}
 
+			for it := obj.Exprs.ElementIterator(); it.Next(); {
+				if client.failedFast(diags) {
+					break
+				}
+				k, v := it.Element()
+				var ex assertionsDRTExpr
+				err := gocty.FromCtyValue(v, &ex)
+				if err != nil {
+					// Should never happen; indicates that our struct is wrong.
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Bug in 'testing' provider",
+						Detail:   fmt.Sprintf("The provider encountered a problem while decoding the expr %q block: %s.\n\nThis is a bug in the provider; please report it in the provider's issue tracker.", k.AsString(), err),
+					})
+					continue
+				}
+
+				path := cty.Path(nil).GetAttr("expr").Index(k)
+				diags = diags.Append(checkAssertionID(ex.ID, ids, path))
+				pass, moreDiags := evalAssertionExpr(ex.Condition, ex.Got, ex.Vars, path)
+				diags = diags.Append(moreDiags)
+				switch {
+				case moreDiags.HasErrors():
+					obj.recordAssertion("expr", k, ex.ID, testResultError)
+					continue
+				case pass:
+					obj.recordAssertion("expr", k, ex.ID, testResultPass)
+					continue
+				}
+				obj.recordAssertion("expr", k, ex.ID, testResultFail)
+
+				statement := ""
+				if ex.Statement != nil {
+					if subject != "" {
+						statement = fmt.Sprintf("%s %s", subject, *ex.Statement)
+					} else {
+						statement = *ex.Statement
+					}
+				}
+
+				msg := fmt.Sprintf(
+					"%s\n  Condition: %s\n  Got:       %s",
+					assertionFailureHeading(ex.ID, statement),
+					ex.Condition,
+					formatValue(ex.Got, 2),
+				)
+
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   msg,
+					Path:     path.GetAttr("condition"),
+				})
+			}
+
 			return obj, diags
 		},
 	})
 }
+
+// recordAssertion adds the outcome of the assertion block of the given type
+// and label to obj.AssertionResults.
+func (obj *assertionsDRT) recordAssertion(blockType string, label cty.Value, id *string, status string) {
+	result := assertionResult{
+		Name:   fmt.Sprintf("%s %q", blockType, label.AsString()),
+		Status: status,
+	}
+	if id != nil {
+		result.ID = *id
+	}
+	obj.AssertionResults = append(obj.AssertionResults, result)
+}
+
+// assertionFailureHeading returns the first line of the error message for a
+// failed assertion, which includes the assertion's id, if any, so that the
+// failure can be recognized even if its statement is later reworded.
+func assertionFailureHeading(id *string, statement string) string {
+	heading := "Assertion failed"
+	if id != nil {
+		heading = fmt.Sprintf("Assertion %q failed", *id)
+	}
+	if statement != "" {
+		return fmt.Sprintf("%s: %s.", heading, statement)
+	}
+	return heading + "."
+}
+
+// checkAssertionID returns an error if the given assertion id is already
+// present in ids, and otherwise records it there. Assertions without an id
+// are always accepted.
+func checkAssertionID(id *string, ids map[string]bool, path cty.Path) tfsdk.Diagnostics {
+	var diags tfsdk.Diagnostics
+	if id == nil {
+		return diags
+	}
+	if ids[*id] {
+		diags = diags.Append(tfsdk.Diagnostic{
+			Severity: tfsdk.Error,
+			Summary:  "Duplicate assertion id",
+			Detail:   fmt.Sprintf("Another assertion in this data source already has the id %q. Each assertion must have a unique id.", *id),
+			Path:     path.GetAttr("id"),
+		})
+		return diags
+	}
+	ids[*id] = true
+	return diags
+}
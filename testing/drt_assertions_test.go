@@ -63,6 +63,109 @@ data "testing_assertions" "test" {
 	expect = false
   }
 }
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("expr pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_assertions" "test" {
+  expr "foo" {
+	condition = "got >= min && upper(name) == \"A\""
+	got       = 5
+	vars = {
+	  min  = 3
+	  name = "a"
+	}
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("expr fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_assertions" "test" {
+  expr "foo" {
+	condition = "got > 10"
+	got       = 5
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("expr invalid", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_assertions" "test" {
+  expr "foo" {
+	condition = "got >"
+	got       = 5
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("id pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_assertions" "test" {
+  check "foo" {
+	id     = "foo-1"
+	expect = true
+  }
+  equal "foo" {
+	id   = "foo-2"
+	got  = 1
+	want = 1
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("id duplicate", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_assertions" "test" {
+  check "foo" {
+	id     = "foo"
+	expect = true
+  }
+  equal "bar" {
+	id   = "foo"
+	got  = 1
+	want = 1
+  }
+}
 `)
 
 		wd.RequireInit(t)
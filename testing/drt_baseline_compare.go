@@ -0,0 +1,121 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type baselineCompareDRT struct {
+	Path    string    `cty:"path"`
+	Values  cty.Value `cty:"values"`
+	Ignore  []string  `cty:"ignore"`
+	Subject *string   `cty:"subject"`
+
+	Baseline cty.Value `cty:"baseline"`
+	Changed  []string  `cty:"changed"`
+}
+
+func baselineCompareDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_baseline_compare", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"path":    {Type: cty.String, Required: true},
+				"values":  {Type: cty.DynamicPseudoType, Required: true},
+				"ignore":  {Type: cty.List(cty.String), Optional: true},
+				"subject": {Type: cty.String, Optional: true},
+
+				"baseline": {Type: cty.DynamicPseudoType, Computed: true},
+				"changed":  {Type: cty.List(cty.String), Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *baselineCompareDRT) (*baselineCompareDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			got, err := baselineElements(obj.Values)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid baseline values",
+					Detail:   fmt.Sprintf("The values to compare %s.", err),
+					Path:     cty.Path(nil).GetAttr("values"),
+				})
+				return obj, diags
+			}
+
+			baseline, err := readBaseline(obj.Path)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Failed to read baseline",
+					Detail:   fmt.Sprintf("Error reading baseline file %s: %s.\n\nUse the testing_baseline_record data source in an earlier run to record the baseline.", obj.Path, err),
+					Path:     cty.Path(nil).GetAttr("path"),
+				})
+				return obj, diags
+			}
+			obj.Baseline = baseline
+			want, _ := baselineElements(baseline) // already checked by readBaseline
+
+			ignore := make(map[string]bool, len(obj.Ignore))
+			for _, k := range obj.Ignore {
+				ignore[k] = true
+			}
+			keys := make([]string, 0, len(want)+len(got))
+			for k := range want {
+				keys = append(keys, k)
+			}
+			for k := range got {
+				if _, exists := want[k]; !exists {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+
+			subject := "value"
+			if obj.Subject != nil {
+				subject = fmt.Sprintf("%s value", *obj.Subject)
+			}
+
+			obj.Changed = []string{}
+			for _, k := range keys {
+				if ignore[k] {
+					continue
+				}
+				wantV, inBaseline := want[k]
+				gotV, inValues := got[k]
+				statement := fmt.Sprintf("%s %q is unchanged since the baseline was recorded", subject, k)
+				var detail string
+				switch {
+				case !inValues:
+					detail = fmt.Sprintf("Assertion failed: %s.\n\nThe baseline includes %q, but the current values do not.", statement, k)
+				case !inBaseline:
+					detail = fmt.Sprintf("Assertion failed: %s.\n\nThe current values include %q, but the baseline does not.", statement, k)
+				case !gotV.RawEquals(wantV):
+					detail = fmt.Sprintf(
+						"Assertion failed: %s.\n  Want: %s\n  Got:  %s",
+						statement,
+						formatValue(wantV, 2),
+						formatValue(gotV, 2),
+					)
+				default:
+					continue
+				}
+
+				obj.Changed = append(obj.Changed, k)
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   detail,
+					Path:     cty.Path(nil).GetAttr("values"),
+				})
+			}
+
+			return obj, diags
+		},
+	})
+}
@@ -0,0 +1,51 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type baselineRecordDRT struct {
+	Path   string    `cty:"path"`
+	Values cty.Value `cty:"values"`
+}
+
+func baselineRecordDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_baseline_record", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"path":   {Type: cty.String, Required: true},
+				"values": {Type: cty.DynamicPseudoType, Required: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *baselineRecordDRT) (*baselineRecordDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			if _, err := baselineElements(obj.Values); err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid baseline values",
+					Detail:   fmt.Sprintf("The baseline values %s.", err),
+					Path:     cty.Path(nil).GetAttr("values"),
+				})
+				return obj, diags
+			}
+
+			if err := writeBaseline(obj.Path, obj.Values); err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Failed to record baseline",
+					Detail:   fmt.Sprintf("Error writing baseline file %s: %s.", obj.Path, err),
+					Path:     cty.Path(nil).GetAttr("path"),
+				})
+			}
+
+			return obj, diags
+		},
+	})
+}
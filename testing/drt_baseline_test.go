@@ -0,0 +1,106 @@
+package testing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDRTBaseline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-testing-baseline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	baselinePath := filepath.Join(dir, "baseline.json")
+
+	t.Run("record", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_baseline_record" "test" {
+  path = %q
+  values = {
+    name    = "example"
+    ports   = tolist([80, 443])
+    version = "1.0.0"
+  }
+}
+`, baselinePath))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("compare pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_baseline_compare" "test" {
+  path = %q
+  values = {
+    name    = "example"
+    ports   = tolist([80, 443])
+    version = "2.0.0"
+  }
+  ignore = ["version"]
+}
+
+data "testing_assertions" "test" {
+  equal "baseline_version" {
+    got  = data.testing_baseline_compare.test.baseline.version
+    want = "1.0.0"
+  }
+  check "changed" {
+    expect = length(data.testing_baseline_compare.test.changed) == 0
+  }
+}
+`, baselinePath))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("compare fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		// The ports are now a tuple rather than a list, which is a change
+		// even though the elements are the same.
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_baseline_compare" "test" {
+  path = %q
+  values = {
+    name    = "example"
+    ports   = [80, 443]
+    version = "1.0.0"
+  }
+}
+`, baselinePath))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("compare missing", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_baseline_compare" "test" {
+  path   = %q
+  values = {}
+}
+`, filepath.Join(dir, "nonexistent.json")))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
@@ -0,0 +1,211 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type benchmarkDRT struct {
+	Program     []string          `cty:"program"`
+	Environment map[string]string `cty:"environment"`
+	Dir         *string           `cty:"dir"`
+	Iterations  *int              `cty:"iterations"`
+	Warmup      *int              `cty:"warmup"`
+	Timeout     *string           `cty:"timeout"`
+	Subject     *string           `cty:"subject"`
+
+	MaxP50      *string `cty:"max_p50"`
+	MaxP95      *string `cty:"max_p95"`
+	MaxDuration *string `cty:"max_duration"`
+
+	Durations []float64 `cty:"durations"`
+	Min       *float64  `cty:"min"`
+	Mean      *float64  `cty:"mean"`
+	P50       *float64  `cty:"p50"`
+	P95       *float64  `cty:"p95"`
+	Max       *float64  `cty:"max"`
+}
+
+func benchmarkDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_benchmark", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"program": {
+					Type:       cty.List(cty.String),
+					Required:   true,
+					ValidateFn: validateProgram,
+				},
+				"environment": {Type: cty.Map(cty.String), Optional: true},
+				"dir":         {Type: cty.String, Optional: true},
+				"iterations":  {Type: cty.Number, Optional: true},
+				"warmup":      {Type: cty.Number, Optional: true},
+				"timeout":     {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"subject":     {Type: cty.String, Optional: true},
+
+				"max_p50":      {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"max_p95":      {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"max_duration": {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+
+				"durations": {Type: cty.List(cty.Number), Computed: true},
+				"min":       {Type: cty.Number, Computed: true},
+				"mean":      {Type: cty.Number, Computed: true},
+				"p50":       {Type: cty.Number, Computed: true},
+				"p95":       {Type: cty.Number, Computed: true},
+				"max":       {Type: cty.Number, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *benchmarkDRT) (*benchmarkDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			iterations := 10
+			if obj.Iterations != nil {
+				iterations = *obj.Iterations
+			}
+			warmup := 0
+			if obj.Warmup != nil {
+				warmup = *obj.Warmup
+			}
+			if iterations < 1 {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid iteration count",
+					Detail:   "The \"iterations\" argument must be at least 1.",
+					Path:     cty.Path(nil).GetAttr("iterations"),
+				})
+			}
+			if warmup < 0 {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid warmup count",
+					Detail:   "The \"warmup\" argument must not be negative.",
+					Path:     cty.Path(nil).GetAttr("warmup"),
+				})
+			}
+			if diags.HasErrors() {
+				return obj, diags
+			}
+			timeout := client.timeout(obj.Timeout, time.Minute)
+			subject := "command"
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+
+			durations := make([]time.Duration, 0, iterations)
+			for i := 0; i < warmup+iterations; i++ {
+				d, err := benchmarkRun(ctx, client, obj, timeout)
+				if err != nil {
+					run := fmt.Sprintf("run %d of %d", i+1-warmup, iterations)
+					if i < warmup {
+						run = fmt.Sprintf("warmup run %d of %d", i+1, warmup)
+					}
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Benchmark command failed",
+						Detail:   fmt.Sprintf("Error during %s of %s: %s.", run, strings.Join(obj.Program, " "), err),
+						Path:     cty.Path(nil).GetAttr("program"),
+					})
+					return obj, diags
+				}
+				if i >= warmup {
+					durations = append(durations, d)
+				}
+			}
+
+			obj.Durations = make([]float64, len(durations))
+			var sum time.Duration
+			for i, d := range durations {
+				obj.Durations[i] = durationMillis(d)
+				sum += d
+			}
+			sorted := append([]time.Duration(nil), durations...)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+			min, max := durationMillis(sorted[0]), durationMillis(sorted[len(sorted)-1])
+			mean := durationMillis(sum / time.Duration(len(sorted)))
+			p50, p95 := durationPercentile(sorted, 50), durationPercentile(sorted, 95)
+			obj.Min, obj.Mean, obj.Max = &min, &mean, &max
+			p50ms, p95ms := durationMillis(p50), durationMillis(p95)
+			obj.P50, obj.P95 = &p50ms, &p95ms
+
+			check := func(what, attr string, limit *string, got time.Duration) {
+				if limit == nil {
+					return
+				}
+				// The limit was already checked by validateDuration.
+				want, _ := time.ParseDuration(*limit)
+				if got <= want {
+					return
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   fmt.Sprintf("Assertion failed: %s completes fast enough.\n  Want %s at most: %s\n  Got %s:          %s", subject, what, want, what, got.Round(time.Microsecond)),
+					Path:     cty.Path(nil).GetAttr(attr),
+				})
+			}
+			check("p50", "max_p50", obj.MaxP50, p50)
+			check("p95", "max_p95", obj.MaxP95, p95)
+			check("max", "max_duration", obj.MaxDuration, sorted[len(sorted)-1])
+
+			return obj, diags
+		},
+	}, ownTimeout)
+}
+
+// benchmarkRun runs the benchmark command once and returns how long it took
+// to complete.
+func benchmarkRun(ctx context.Context, client *Client, obj *benchmarkDRT, timeout time.Duration) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := programCommand(ctx, client, obj.Program, obj.Environment)
+	if obj.Dir != nil {
+		cmd.Dir = *obj.Dir
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	// We wait for a free slot before starting the timer, so that waiting
+	// for other programs doesn't count towards the result.
+	release, err := client.acquireProgram(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	start := time.Now()
+	err = cmd.Run()
+	d := time.Since(start)
+	if ctx.Err() == context.DeadlineExceeded {
+		return d, fmt.Errorf("did not complete within %s", timeout)
+	}
+	if err != nil && stderr.Len() != 0 {
+		err = fmt.Errorf("%s\n\n%s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return d, err
+}
+
+// durationPercentile returns the given percentile of the given durations,
+// which must already be sorted in increasing order, using the nearest-rank
+// method.
+func durationPercentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// durationMillis returns the given duration as a fractional number of
+// milliseconds, the unit this provider uses for durations it reports.
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,95 @@
+package testing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDRTBenchmark(t *testing.T) {
+	t.Run("pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_benchmark" "test" {
+  program    = ["true"]
+  iterations = 5
+  warmup     = 1
+
+  max_p50      = "10s"
+  max_p95      = "10s"
+  max_duration = "10s"
+}
+
+data "testing_assertions" "test" {
+  equal "count" {
+    got  = length(data.testing_benchmark.test.durations)
+    want = 5
+  }
+  check "order" {
+    expect = (
+      data.testing_benchmark.test.min <= data.testing_benchmark.test.p50 &&
+      data.testing_benchmark.test.p50 <= data.testing_benchmark.test.p95 &&
+      data.testing_benchmark.test.p95 <= data.testing_benchmark.test.max
+    )
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("too slow", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_benchmark" "test" {
+  program    = ["sleep", "0.1"]
+  iterations = 2
+  max_p50    = "1ms"
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("command fails", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_benchmark" "test" {
+  program = ["false"]
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
+
+func TestDurationPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, 1},
+		{50, 5},
+		{90, 9},
+		{95, 10},
+		{100, 10},
+	}
+	for _, test := range tests {
+		if got := durationPercentile(sorted, test.p); got != test.want {
+			t.Errorf("p%v: got %d, want %d", test.p, got, test.want)
+		}
+	}
+}
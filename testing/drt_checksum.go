@@ -0,0 +1,168 @@
+package testing
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type checksumDRT struct {
+	File      *string `cty:"file"`
+	URL       *string `cty:"url"`
+	Algorithm *string `cty:"algorithm"`
+	Expected  *string `cty:"expected"`
+	Subject   *string `cty:"subject"`
+
+	Digest       *string `cty:"digest"`
+	DigestBase64 *string `cty:"digest_base64"`
+}
+
+// checksumAlgorithms are the hash functions that testing_checksum supports.
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+func checksumDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_checksum", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"file": {Type: cty.String, Optional: true},
+				"url":  {Type: cty.String, Optional: true},
+				"algorithm": {
+					Type:     cty.String,
+					Optional: true,
+					ValidateFn: func(v string) tfsdk.Diagnostics {
+						var diags tfsdk.Diagnostics
+						if checksumAlgorithms[v] == nil {
+							diags = diags.Append(tfsdk.ValidationError(
+								cty.Path(nil).NewErrorf("must be one of \"md5\", \"sha1\", \"sha256\", or \"sha512\""),
+							))
+						}
+						return diags
+					},
+				},
+				"expected": {Type: cty.String, Optional: true},
+				"subject":  {Type: cty.String, Optional: true},
+
+				"digest":        {Type: cty.String, Computed: true},
+				"digest_base64": {Type: cty.String, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *checksumDRT) (*checksumDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			if (obj.File == nil) == (obj.URL == nil) {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid checksum source",
+					Detail:   "Exactly one of the \"file\" and \"url\" arguments must be set, to select what to compute the checksum of.",
+				})
+				return obj, diags
+			}
+
+			algorithm := "sha256"
+			if obj.Algorithm != nil {
+				algorithm = *obj.Algorithm
+			}
+			h := checksumAlgorithms[algorithm]()
+
+			var source string
+			var err error
+			if obj.File != nil {
+				source = *obj.File
+				err = checksumFile(h, source)
+			} else {
+				source = *obj.URL
+				err = checksumURL(ctx, h, source)
+			}
+			if err != nil {
+				attr := "file"
+				if obj.URL != nil {
+					attr = "url"
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Failed to compute checksum",
+					Detail:   fmt.Sprintf("Error reading %s: %s.", source, err),
+					Path:     cty.Path(nil).GetAttr(attr),
+				})
+				return obj, diags
+			}
+
+			sum := h.Sum(nil)
+			digest := hex.EncodeToString(sum)
+			digestBase64 := base64.StdEncoding.EncodeToString(sum)
+			obj.Digest = &digest
+			obj.DigestBase64 = &digestBase64
+
+			if obj.Expected != nil && !checksumMatches(*obj.Expected, sum) {
+				statement := fmt.Sprintf("%s has the expected %s checksum", source, algorithm)
+				if obj.Subject != nil {
+					statement = fmt.Sprintf("%s has the expected %s checksum", *obj.Subject, algorithm)
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   namedAssertionFailureMsg(statement, "digest", cty.StringVal(*obj.Expected), cty.StringVal(digest)),
+					Path:     cty.Path(nil).GetAttr("expected"),
+				})
+			}
+
+			return obj, diags
+		},
+	})
+}
+
+// checksumMatches reports whether the given expected digest, which may be
+// either hex or base64 encoded, matches the given raw digest.
+func checksumMatches(expected string, sum []byte) bool {
+	if strings.EqualFold(expected, hex.EncodeToString(sum)) {
+		return true
+	}
+	return expected == base64.StdEncoding.EncodeToString(sum)
+}
+
+func checksumFile(h hash.Hash, filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(h, f)
+	return err
+}
+
+func checksumURL(ctx context.Context, h hash.Hash, url string) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	_, err = io.Copy(h, resp.Body)
+	return err
+}
@@ -0,0 +1,103 @@
+package testing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDRTChecksum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-testing-checksum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "artifact.txt")
+	if err := ioutil.WriteFile(filename, []byte("hello world\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/artifact.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("hello world\n"))
+	}))
+	defer server.Close()
+
+	t.Run("file pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_checksum" "test" {
+  file     = %q
+  expected = "A948904F2F0F479B8F8197694B30184B0D2ED1C1CD2A1EC0FB85D299A192A447"
+}
+
+data "testing_assertions" "test" {
+  equal "base64" {
+    got  = data.testing_checksum.test.digest_base64
+    want = "qUiQTy8PR5uPgZdpSzAYSw0u0cHNKh7A+4XSmaGSpEc="
+  }
+}
+`, filename))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("url pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_checksum" "test" {
+  url       = "%s/artifact.txt"
+  algorithm = "md5"
+  expected  = "6f5902ac237024bdd0c176cb93063dc4"
+}
+`, server.URL))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("file fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_checksum" "test" {
+  file      = %q
+  algorithm = "sha1"
+  expected  = "0000000000000000000000000000000000000000"
+}
+`, filename))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("url not found", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_checksum" "test" {
+  url = "%s/missing.txt"
+}
+`, server.URL))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
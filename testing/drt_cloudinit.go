@@ -0,0 +1,139 @@
+package testing
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type cloudinitDRT struct {
+	UserData      string `cty:"user_data"`
+	Base64Encoded *bool  `cty:"base64_encoded"`
+
+	Parts        []cloudinitPart `cty:"parts"`
+	CloudConfigs cty.Value       `cty:"cloud_configs"`
+}
+
+type cloudinitPart struct {
+	ContentType string  `cty:"content_type"`
+	Filename    *string `cty:"filename"`
+}
+
+func cloudinitDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_cloudinit", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"user_data":      {Type: cty.String, Required: true},
+				"base64_encoded": {Type: cty.Bool, Optional: true},
+
+				"parts": {
+					Type: cty.List(cty.Object(map[string]cty.Type{
+						"content_type": cty.String,
+						"filename":     cty.String,
+					})),
+					Computed: true,
+				},
+				"cloud_configs": {Type: cty.DynamicPseudoType, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *cloudinitDRT) (*cloudinitDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+			// The user data is what's being tested, so problems with it
+			// are test failures rather than configuration errors.
+			invalid := func(detail string) {
+				if client.failedFast(diags) {
+					return
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   "Invalid cloud-init user data: " + detail,
+					Path:     cty.Path(nil).GetAttr("user_data"),
+				})
+			}
+
+			src := []byte(obj.UserData)
+			if obj.Base64Encoded != nil && *obj.Base64Encoded {
+				var err error
+				src, err = base64.StdEncoding.DecodeString(strings.TrimSpace(obj.UserData))
+				if err != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Invalid base64 user data",
+						Detail:   fmt.Sprintf("The user data is not valid base64, but base64_encoded is set: %s.", err),
+						Path:     cty.Path(nil).GetAttr("user_data"),
+					})
+					return obj, diags
+				}
+			}
+
+			parts, err := parseUserData(src)
+			if err != nil {
+				invalid(fmt.Sprintf("The user data is invalid: %s.", err))
+				return obj, diags
+			}
+
+			obj.Parts = make([]cloudinitPart, len(parts))
+			var configs []cty.Value
+			for i, part := range parts {
+				obj.Parts[i].ContentType = part.ContentType
+				if part.Filename != "" {
+					filename := part.Filename
+					obj.Parts[i].Filename = &filename
+				}
+
+				switch part.ContentType {
+				case "text/cloud-config":
+					docs, err := parseYAMLDocuments(string(part.Content))
+					if err != nil {
+						invalid(fmt.Sprintf("The cloud-config in %s is not valid YAML: %s.", part.Location, strings.TrimPrefix(err.Error(), "yaml: ")))
+						continue
+					}
+					if len(docs) > 1 {
+						invalid(fmt.Sprintf("The cloud-config in %s contains %d YAML documents, but cloud-init reads only the first one.", part.Location, len(docs)))
+						continue
+					}
+					doc := cty.NullVal(cty.DynamicPseudoType)
+					if len(docs) == 1 {
+						doc = docs[0]
+					}
+					errs, unknownKeys := validateCloudConfig(doc)
+					for _, err := range errs {
+						location, msg := cloudConfigErrorLocation(part, err)
+						invalid(fmt.Sprintf("The cloud-config in %s is invalid: %s.", location, msg))
+					}
+					for _, key := range unknownKeys {
+						diags = diags.Append(tfsdk.Diagnostic{
+							Severity: tfsdk.Warning,
+							Summary:  "Unrecognized cloud-config key",
+							Detail:   fmt.Sprintf("The cloud-config in %s contains the key %q, which is not a cloud-config setting this provider recognizes. If this is not a misspelling of another key, it may be a setting for a cloud-init module this provider doesn't know about.", part.Location, key),
+							Path:     cty.Path(nil).GetAttr("user_data"),
+						})
+					}
+					configs = append(configs, doc)
+				case "text/cloud-config-archive":
+					if _, err := parseYAMLDocuments(string(part.Content)); err != nil {
+						invalid(fmt.Sprintf("The cloud-config archive in %s is not valid YAML: %s.", part.Location, strings.TrimPrefix(err.Error(), "yaml: ")))
+					}
+				case "text/x-shellscript":
+					if !strings.HasPrefix(string(part.Content), "#!") {
+						invalid(fmt.Sprintf("The shell script in %s must start with an interpreter line like #!/bin/sh, because cloud-init runs it as an executable.", part.Location))
+					}
+				}
+			}
+
+			obj.CloudConfigs = cty.EmptyTupleVal
+			if len(configs) > 0 {
+				obj.CloudConfigs = cty.TupleVal(configs)
+			}
+
+			return obj, diags
+		},
+	})
+}
@@ -0,0 +1,161 @@
+package testing
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDRTCloudinit(t *testing.T) {
+	t.Run("cloud-config", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_cloudinit" "test" {
+  user_data = <<-EOT
+    #cloud-config
+    package_update: true
+    packages:
+      - nginx
+      - [libpython2.7, 2.7.3-0ubuntu3.1]
+    write_files:
+      - path: /etc/motd
+        content: Hello
+        permissions: "0644"
+    runcmd:
+      - [systemctl, start, nginx]
+      - echo done
+  EOT
+}
+
+data "testing_assertions" "test" {
+  equal "packages" {
+    got  = data.testing_cloudinit.test.cloud_configs[0].packages[0]
+    want = "nginx"
+  }
+  equal "content_type" {
+    got  = data.testing_cloudinit.test.parts[0].content_type
+    want = "text/cloud-config"
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("multipart", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_cloudinit" "test" {
+  base64_encoded = true
+  user_data = base64encode(<<-EOT
+    Content-Type: multipart/mixed; boundary="BOUNDARY"
+    MIME-Version: 1.0
+
+    --BOUNDARY
+    Content-Type: text/cloud-config
+    Content-Disposition: attachment; filename="config.yaml"
+
+    hostname: example
+    --BOUNDARY
+    Content-Type: text/x-shellscript
+    Content-Disposition: attachment; filename="setup.sh"
+
+    #!/bin/sh
+    echo hello
+    --BOUNDARY--
+  EOT
+  )
+}
+
+data "testing_assertions" "test" {
+  equal "parts" {
+    got = data.testing_cloudinit.test.parts
+    want = tolist([
+      { content_type = "text/cloud-config", filename = "config.yaml" },
+      { content_type = "text/x-shellscript", filename = "setup.sh" },
+    ])
+  }
+  equal "hostname" {
+    got  = data.testing_cloudinit.test.cloud_configs[0].hostname
+    want = "example"
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("transfer encodings", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_cloudinit" "test" {
+  user_data = <<-EOT
+    Content-Type: multipart/mixed; boundary="BOUNDARY"
+    MIME-Version: 1.0
+
+    --BOUNDARY
+    Content-Type: text/cloud-config
+    Content-Transfer-Encoding: base64
+
+    ${base64encode("hostname: example\n")}
+    --BOUNDARY
+    Content-Type: text/cloud-config
+    Content-Transfer-Encoding: quoted-printable
+
+    timezone: Europe/=
+    London
+    --BOUNDARY--
+  EOT
+}
+
+data "testing_assertions" "test" {
+  equal "hostname" {
+    got  = data.testing_cloudinit.test.cloud_configs[0].hostname
+    want = "example"
+  }
+  equal "timezone" {
+    got  = data.testing_cloudinit.test.cloud_configs[1].timezone
+    want = "Europe/London"
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for name, userData := range map[string]string{
+			"syntax":        "#cloud-config\npackages: [nginx\n",
+			"schema":        "#cloud-config\nwrite_files:\n  - content: Hello\n",
+			"type":          "#cloud-config\npackage_update: [true]\n",
+			"format":        "packages: [nginx]\n",
+			"script":        "Content-Type: multipart/mixed; boundary=B\n\n--B\nContent-Type: text/x-shellscript\n\necho hello\n--B--\n",
+			"not a mapping": "#cloud-config\n- nginx\n",
+		} {
+			t.Run(name, func(t *testing.T) {
+				wd := testHelper.RequireNewWorkingDir(t)
+				defer wd.Close()
+
+				wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_cloudinit" "test" {
+  user_data = %q
+}
+`, userData))
+				wd.RequireInit(t)
+				err := wd.Apply()
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if !strings.Contains(err.Error(), "Test failure") {
+					t.Errorf("error is not a test failure\n%s", err)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,181 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/hashicorp/hcl2/ext/typeexpr"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+type contractDRT struct {
+	Subject    *string   `cty:"subject"`
+	Outputs    cty.Value `cty:"outputs"`
+	AllowExtra *bool     `cty:"allow_extra"`
+
+	OutputContracts cty.Value `cty:"output"`
+}
+
+type contractDRTOutput struct {
+	Type     *string `cty:"type"`
+	Nullable *bool   `cty:"nullable"`
+	Required *bool   `cty:"required"`
+}
+
+func contractDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_contract", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"subject":     {Type: cty.String, Optional: true},
+				"outputs":     {Type: cty.DynamicPseudoType, Required: true},
+				"allow_extra": {Type: cty.Bool, Optional: true},
+			},
+			NestedBlockTypes: map[string]*tfschema.NestedBlockType{
+				"output": {
+					Nesting: tfschema.NestingMap,
+					Content: tfschema.BlockType{
+						Attributes: map[string]*tfschema.Attribute{
+							"type": {
+								Type:     cty.String,
+								Optional: true,
+								ValidateFn: func(v string) tfsdk.Diagnostics {
+									_, hclDiags := parseTypeConstraint(v)
+									return convertHCLDiagnostics(hclDiags, "type constraint", nil)
+								},
+							},
+							"nullable": {Type: cty.Bool, Optional: true},
+							"required": {Type: cty.Bool, Optional: true},
+						},
+					},
+				},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *contractDRT) (*contractDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			subject := "Module"
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+
+			outputsTy := obj.Outputs.Type()
+			if obj.Outputs.IsNull() || !(outputsTy.IsObjectType() || outputsTy.IsMapType()) {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid outputs value",
+					Detail:   "The outputs argument must be an object or map whose attributes are the module's output values, such as a whole module object like module.example.",
+					Path:     cty.Path(nil).GetAttr("outputs"),
+				})
+				return obj, diags
+			}
+			outputs := obj.Outputs.AsValueMap()
+			declared := make(map[string]bool)
+
+			for it := obj.OutputContracts.ElementIterator(); it.Next(); {
+				k, v := it.Element()
+				name := k.AsString()
+				declared[name] = true
+				if client.failedFast(diags) {
+					// We still visit the remaining contracts, so that
+					// allow_extra knows which outputs are declared.
+					continue
+				}
+				var oc contractDRTOutput
+				err := gocty.FromCtyValue(v, &oc)
+				if err != nil {
+					// Should never happen; indicates that our struct is wrong.
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Bug in 'testing' provider",
+						Detail:   fmt.Sprintf("The provider encountered a problem while decoding the output %q block: %s.\n\nThis is a bug in the provider; please report it in the provider's issue tracker.", name, err),
+					})
+					continue
+				}
+				path := cty.Path(nil).GetAttr("output").Index(k)
+
+				val, exists := outputs[name]
+				if !exists {
+					if oc.Required == nil || *oc.Required {
+						diags = diags.Append(tfsdk.Diagnostic{
+							Severity: tfsdk.Error,
+							Summary:  "Test failure",
+							Detail:   fmt.Sprintf("Contract violation: %s does not have the required output value %q.", subject, name),
+							Path:     path,
+						})
+					}
+					continue
+				}
+
+				if val.IsNull() {
+					if oc.Nullable != nil && !*oc.Nullable {
+						diags = diags.Append(tfsdk.Diagnostic{
+							Severity: tfsdk.Error,
+							Summary:  "Test failure",
+							Detail:   fmt.Sprintf("Contract violation: %s output value %q must not be null.", subject, name),
+							Path:     path.GetAttr("nullable"),
+						})
+					}
+					continue
+				}
+
+				if oc.Type != nil {
+					ty, hclDiags := parseTypeConstraint(*oc.Type)
+					if hclDiags.HasErrors() {
+						// Should never happen because we validated this already.
+						diags = diags.Append(convertHCLDiagnostics(hclDiags, "type constraint", path.GetAttr("type")))
+						continue
+					}
+					if _, err := convert.Convert(val, ty); err != nil {
+						diags = diags.Append(tfsdk.Diagnostic{
+							Severity: tfsdk.Error,
+							Summary:  "Test failure",
+							Detail:   fmt.Sprintf("Contract violation: %s output value %q does not conform to type %s: %s.\n  Got: %s", subject, name, typeexpr.TypeString(ty), tfsdk.FormatError(err), formatValue(val, 2)),
+							Path:     path.GetAttr("type"),
+						})
+					}
+				}
+			}
+
+			if obj.AllowExtra != nil && !*obj.AllowExtra && !client.failedFast(diags) {
+				var extra []string
+				for name := range outputs {
+					if !declared[name] {
+						extra = append(extra, name)
+					}
+				}
+				if len(extra) > 0 {
+					sort.Strings(extra)
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   fmt.Sprintf("Contract violation: %s has output values not declared in the contract: %s.", subject, strings.Join(extra, ", ")),
+						Path:     cty.Path(nil).GetAttr("allow_extra"),
+					})
+				}
+			}
+
+			return obj, diags
+		},
+	})
+}
+
+// parseTypeConstraint parses the given string as a type constraint using the
+// same syntax as the "type" argument in a Terraform variable block.
+func parseTypeConstraint(src string) (cty.Type, hcl.Diagnostics) {
+	expr, diags := hclsyntax.ParseExpression([]byte(src), "type", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+	if diags.HasErrors() {
+		return cty.DynamicPseudoType, diags
+	}
+	ty, moreDiags := typeexpr.TypeConstraint(expr)
+	diags = append(diags, moreDiags...)
+	return ty, diags
+}
@@ -0,0 +1,112 @@
+package testing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDRTContract(t *testing.T) {
+	t.Run("pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_contract" "test" {
+  outputs = {
+	name = "example"
+	ids  = ["a", "b"]
+	tags = null
+  }
+  allow_extra = false
+
+  output "name" {
+	type     = "string"
+	nullable = false
+  }
+  output "ids" {
+	type = "list(string)"
+  }
+  output "tags" {
+	type = "map(string)"
+  }
+  output "legacy" {
+	required = false
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("missing output", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_contract" "test" {
+  outputs = {
+	name = "example"
+  }
+
+  output "id" {
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Fatal("succeeded; want error")
+		}
+		for _, want := range []string{"Test failure", "Contract violation: Module does not have the required output value \"id\""} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error does not mention %q\n%s", want, err)
+			}
+		}
+	})
+	t.Run("wrong type", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_contract" "test" {
+  outputs = {
+	ids = "a,b"
+  }
+
+  output "ids" {
+	type = "list(string)"
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("extra output", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_contract" "test" {
+  outputs = {
+	name  = "example"
+	extra = "surprise"
+  }
+  allow_extra = false
+
+  output "name" {
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
@@ -0,0 +1,271 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type dnsConsistencyDRT struct {
+	Name      string   `cty:"name"`
+	Type      *string  `cty:"type"`
+	Resolvers []string `cty:"resolvers"`
+	Expected  []string `cty:"expected"`
+	Timeout   *string  `cty:"timeout"`
+	Subject   *string  `cty:"subject"`
+
+	Answers    map[string][]string `cty:"answers"`
+	Consistent *bool               `cty:"consistent"`
+}
+
+func dnsConsistencyDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_dns_consistency", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"name":      {Type: cty.String, Required: true},
+				"type":      {Type: cty.String, Optional: true, ValidateFn: validateDNSLookupType},
+				"resolvers": {Type: cty.List(cty.String), Required: true},
+				"expected":  {Type: cty.Set(cty.String), Optional: true},
+				"timeout":   {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"subject":   {Type: cty.String, Optional: true},
+
+				"answers":    {Type: cty.Map(cty.List(cty.String)), Computed: true},
+				"consistent": {Type: cty.Bool, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *dnsConsistencyDRT) (*dnsConsistencyDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			if len(obj.Resolvers) == 0 {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "No resolvers",
+					Detail:   "The \"resolvers\" argument must contain at least one resolver address.",
+					Path:     cty.Path(nil).GetAttr("resolvers"),
+				})
+				return obj, diags
+			}
+			qtype := "A"
+			if obj.Type != nil {
+				qtype = strings.ToUpper(*obj.Type)
+			}
+			timeout := client.timeout(obj.Timeout, 5*time.Second)
+			subject := obj.Name
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+
+			answers := make([][]string, len(obj.Resolvers))
+			errs := make([]error, len(obj.Resolvers))
+			var wg sync.WaitGroup
+			for i, addr := range obj.Resolvers {
+				wg.Add(1)
+				go func(i int, addr string) {
+					defer wg.Done()
+					ctx, cancel := context.WithTimeout(ctx, timeout)
+					defer cancel()
+					answers[i], errs[i] = dnsLookup(ctx, dnsResolver(addr), obj.Name, qtype)
+				}(i, addr)
+			}
+			wg.Wait()
+
+			obj.Answers = make(map[string][]string, len(obj.Resolvers))
+			for i, addr := range obj.Resolvers {
+				if errs[i] != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "DNS lookup failed",
+						Detail:   fmt.Sprintf("Error looking up %s records for %s using resolver %s: %s.", qtype, obj.Name, addr, errs[i]),
+						Path:     cty.Path(nil).GetAttr("resolvers").Index(cty.NumberIntVal(int64(i))),
+					})
+					continue
+				}
+				obj.Answers[addr] = answers[i]
+			}
+			if diags.HasErrors() {
+				return obj, diags
+			}
+
+			consistent := true
+			for _, answer := range answers[1:] {
+				if !stringSlicesEqual(answer, answers[0]) {
+					consistent = false
+				}
+			}
+			obj.Consistent = &consistent
+
+			if obj.Expected != nil {
+				want := append([]string(nil), obj.Expected...)
+				for i := range want {
+					want[i] = normalizeDNSAnswer(qtype, want[i])
+				}
+				sort.Strings(want)
+				for i, addr := range obj.Resolvers {
+					if client.failedFast(diags) {
+						break
+					}
+					if stringSlicesEqual(answers[i], want) {
+						continue
+					}
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   namedAssertionFailureMsg(fmt.Sprintf("%s has the expected %s records according to %s", subject, qtype, addr), "records", stringSliceVal(want), stringSliceVal(answers[i])),
+						Path:     cty.Path(nil).GetAttr("expected"),
+					})
+				}
+			} else if !consistent {
+				var buf strings.Builder
+				fmt.Fprintf(&buf, "Assertion failed: %s has the same %s records according to all resolvers.", subject, qtype)
+				for i, addr := range obj.Resolvers {
+					fmt.Fprintf(&buf, "\n  %s: %s", addr, formatValue(stringSliceVal(answers[i]), 2))
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   buf.String(),
+					Path:     cty.Path(nil).GetAttr("resolvers"),
+				})
+			}
+
+			return obj, diags
+		},
+	}, ownTimeout)
+}
+
+// dnsResolver returns a resolver that sends all of its queries to the DNS
+// server at the given address, which uses port 53 if it doesn't include a
+// port number.
+func dnsResolver(addr string) *net.Resolver {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+}
+
+// dnsLookup looks up the records of the given type for the given name and
+// returns them as a sorted list of strings, using the same syntax as in a
+// zone file. A name that doesn't exist has no records, rather than being an
+// error, because that is a valid answer when checking for propagation.
+func dnsLookup(ctx context.Context, r *net.Resolver, name, qtype string) ([]string, error) {
+	var answers []string
+	var err error
+	switch qtype {
+	case "A", "AAAA":
+		var addrs []net.IPAddr
+		addrs, err = r.LookupIPAddr(ctx, name)
+		for _, addr := range addrs {
+			if (addr.IP.To4() != nil) == (qtype == "A") {
+				answers = append(answers, addr.IP.String())
+			}
+		}
+	case "CNAME":
+		var cname string
+		cname, err = r.LookupCNAME(ctx, name)
+		if err == nil && normalizeDNSAnswer(qtype, cname) != normalizeDNSAnswer(qtype, name) {
+			answers = append(answers, cname)
+		}
+	case "MX":
+		var mxs []*net.MX
+		mxs, err = r.LookupMX(ctx, name)
+		for _, mx := range mxs {
+			answers = append(answers, fmt.Sprintf("%d %s", mx.Pref, mx.Host))
+		}
+	case "NS":
+		var nss []*net.NS
+		nss, err = r.LookupNS(ctx, name)
+		for _, ns := range nss {
+			answers = append(answers, ns.Host)
+		}
+	case "TXT":
+		answers, err = r.LookupTXT(ctx, name)
+	default:
+		// Should never happen because validateDNSLookupType checks this.
+		return nil, fmt.Errorf("unsupported record type %q", qtype)
+	}
+	if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+		answers, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range answers {
+		answers[i] = normalizeDNSAnswer(qtype, answers[i])
+	}
+	sort.Strings(answers)
+	return answers, nil
+}
+
+// normalizeDNSAnswer returns a canonical form of the given record data of
+// the given type, so that differences in IP address syntax, or in letter case
+// and trailing dots in domain names, don't count as differences between
+// answers.
+func normalizeDNSAnswer(qtype, s string) string {
+	switch qtype {
+	case "A", "AAAA":
+		if ip := net.ParseIP(s); ip != nil {
+			return ip.String()
+		}
+		return s
+	case "TXT":
+		return s
+	default:
+		return strings.TrimSuffix(strings.ToLower(s), ".")
+	}
+}
+
+// validateDNSLookupType is a ValidateFn for arguments that select one of the
+// DNS record types that dnsLookup supports.
+func validateDNSLookupType(v string) tfsdk.Diagnostics {
+	var diags tfsdk.Diagnostics
+	switch strings.ToUpper(v) {
+	case "A", "AAAA", "CNAME", "MX", "NS", "TXT":
+	default:
+		diags = diags.Append(tfsdk.ValidationError(
+			cty.Path(nil).NewErrorf("must be one of \"A\", \"AAAA\", \"CNAME\", \"MX\", \"NS\", or \"TXT\""),
+		))
+	}
+	return diags
+}
+
+// stringSlicesEqual returns true if the two given slices have the same
+// elements in the same order.
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// stringSliceVal returns a list value containing the given strings, for use
+// in assertion failure messages.
+func stringSliceVal(s []string) cty.Value {
+	if len(s) == 0 {
+		return cty.ListValEmpty(cty.String)
+	}
+	vals := make([]cty.Value, len(s))
+	for i, v := range s {
+		vals[i] = cty.StringVal(v)
+	}
+	return cty.ListVal(vals)
+}
@@ -0,0 +1,120 @@
+package testing
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// testDNSServer starts a DNS server that answers every A query with the
+// given addresses, and returns its address.
+func testDNSServer(t *testing.T, addrs ...[4]byte) (string, func()) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var req dnsmessage.Message
+			if err := req.Unpack(buf[:n]); err != nil || len(req.Questions) != 1 {
+				continue
+			}
+			q := req.Questions[0]
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: req.ID, Response: true, Authoritative: true},
+				Questions: req.Questions,
+			}
+			if q.Type == dnsmessage.TypeA {
+				for _, addr := range addrs {
+					resp.Answers = append(resp.Answers, dnsmessage.Resource{
+						Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+						Body:   &dnsmessage.AResource{A: addr},
+					})
+				}
+			}
+			msg, err := resp.Pack()
+			if err != nil {
+				continue
+			}
+			conn.WriteTo(msg, from)
+		}
+	}()
+	return conn.LocalAddr().String(), func() { conn.Close() }
+}
+
+func TestDRTDNSConsistency(t *testing.T) {
+	a, closeA := testDNSServer(t, [4]byte{192, 0, 2, 1}, [4]byte{192, 0, 2, 2})
+	defer closeA()
+	b, closeB := testDNSServer(t, [4]byte{192, 0, 2, 2}, [4]byte{192, 0, 2, 1})
+	defer closeB()
+	stale, closeStale := testDNSServer(t, [4]byte{198, 51, 100, 1})
+	defer closeStale()
+
+	t.Run("consistent", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_dns_consistency" "test" {
+  name      = "www.example.com"
+  resolvers = [%q, %q]
+  expected  = ["192.0.2.1", "192.0.2.2"]
+}
+
+data "testing_assertions" "test" {
+  equal "answer" {
+    got  = data.testing_dns_consistency.test.answers[%q]
+    want = tolist(["192.0.2.1", "192.0.2.2"])
+  }
+  check "consistent" {
+    expect = data.testing_dns_consistency.test.consistent
+  }
+}
+`, a, b, b))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("inconsistent", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_dns_consistency" "test" {
+  name      = "www.example.com"
+  resolvers = [%q, %q]
+}
+`, a, stale))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("unexpected", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_dns_consistency" "test" {
+  name      = "www.example.com"
+  resolvers = [%q]
+  expected  = ["192.0.2.1", "192.0.2.2"]
+}
+`, stale))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
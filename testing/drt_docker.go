@@ -0,0 +1,320 @@
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type dockerDRT struct {
+	Host      *string `cty:"host"`
+	Container *string `cty:"container"`
+	Image     *string `cty:"image"`
+
+	Running      *bool             `cty:"running"`
+	HealthStatus *string           `cty:"health_status"`
+	ExposedPorts []string          `cty:"exposed_ports"`
+	Labels       map[string]string `cty:"labels"`
+
+	ID     *string `cty:"id"`
+	Status *string `cty:"status"`
+}
+
+// dockerInspect is the subset of the Docker Engine API's container and image
+// inspect responses that testing_docker uses. The two responses share the
+// fields we need, except that only containers have State.
+type dockerInspect struct {
+	ID    string `json:"Id"`
+	State *struct {
+		Status  string
+		Running bool
+		Health  *struct {
+			Status string
+		}
+	}
+	Config struct {
+		Labels       map[string]string
+		ExposedPorts map[string]struct{}
+	}
+}
+
+func dockerDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_docker", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"host":      {Type: cty.String, Optional: true},
+				"container": {Type: cty.String, Optional: true},
+				"image":     {Type: cty.String, Optional: true},
+
+				"running":       {Type: cty.Bool, Optional: true, Computed: true},
+				"health_status": {Type: cty.String, Optional: true, Computed: true},
+				"exposed_ports": {Type: cty.List(cty.String), Optional: true, Computed: true},
+				"labels":        {Type: cty.Map(cty.String), Optional: true, Computed: true},
+
+				"id":     {Type: cty.String, Computed: true},
+				"status": {Type: cty.String, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *dockerDRT) (*dockerDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			if (obj.Container == nil) == (obj.Image == nil) {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid Docker configuration",
+					Detail:   "Exactly one of the \"container\" and \"image\" arguments must be set, to specify which object to inspect.",
+				})
+				return obj, diags
+			}
+			if obj.Image != nil && (obj.Running != nil || obj.HealthStatus != nil) {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid Docker configuration",
+					Detail:   "The \"running\" and \"health_status\" arguments apply only to containers, not to images.",
+				})
+				return obj, diags
+			}
+
+			host := os.Getenv("DOCKER_HOST")
+			if obj.Host != nil {
+				host = *obj.Host
+			}
+			if host == "" {
+				host = "unix:///var/run/docker.sock"
+			}
+			httpClient, baseURL, err := dockerHTTPClient(host)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid Docker host",
+					Detail:   fmt.Sprintf("Cannot connect to Docker host %q: %s.", host, err),
+					Path:     cty.Path(nil).GetAttr("host"),
+				})
+				return obj, diags
+			}
+
+			var kind, name, reqPath string
+			if obj.Container != nil {
+				kind, name = "container", *obj.Container
+				reqPath = "/containers/" + url.PathEscape(name) + "/json"
+			} else {
+				kind, name = "image", *obj.Image
+				reqPath = "/images/" + url.PathEscape(name) + "/json"
+			}
+
+			req, err := http.NewRequest("GET", baseURL+reqPath, nil)
+			if err != nil {
+				// Should never happen, since we constructed the URL ourselves.
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Bug in 'testing' provider",
+					Detail:   fmt.Sprintf("The provider constructed an invalid Docker API request: %s.\n\nThis is a bug in the provider; please report it in the provider's issue tracker.", err),
+				})
+				return obj, diags
+			}
+			resp, err := httpClient.Do(req.WithContext(ctx))
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Docker API request failed",
+					Detail:   fmt.Sprintf("Failed to inspect %s %q: %s.", kind, name, err),
+				})
+				return obj, diags
+			}
+			defer resp.Body.Close()
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Docker API request failed",
+					Detail:   fmt.Sprintf("Failed to read the response when inspecting %s %q: %s.", kind, name, err),
+				})
+				return obj, diags
+			}
+
+			switch {
+			case resp.StatusCode == http.StatusNotFound:
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   fmt.Sprintf("Docker %s %q does not exist.", kind, name),
+					Path:     cty.Path(nil).GetAttr(kind),
+				})
+				return obj, diags
+			case resp.StatusCode != http.StatusOK:
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Docker API request failed",
+					Detail:   fmt.Sprintf("Failed to inspect %s %q: the Docker API returned %s: %s.", kind, name, resp.Status, strings.TrimSpace(string(body))),
+				})
+				return obj, diags
+			}
+
+			var info dockerInspect
+			if err := json.Unmarshal(body, &info); err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Docker API request failed",
+					Detail:   fmt.Sprintf("Failed to decode the response when inspecting %s %q: %s.", kind, name, err),
+				})
+				return obj, diags
+			}
+
+			subject := fmt.Sprintf("Docker %s %q", kind, name)
+			gotPorts := make([]string, 0, len(info.Config.ExposedPorts))
+			for port := range info.Config.ExposedPorts {
+				gotPorts = append(gotPorts, port)
+			}
+			sort.Strings(gotPorts)
+			gotLabels := info.Config.Labels
+			if gotLabels == nil {
+				gotLabels = map[string]string{}
+			}
+
+			if info.State != nil {
+				gotHealth := "none"
+				if info.State.Health != nil {
+					gotHealth = info.State.Health.Status
+				}
+				if obj.Running != nil && *obj.Running != info.State.Running {
+					diags = diags.Append(dockerAssertionFailure(
+						fmt.Sprintf("%s has expected running state", subject),
+						cty.BoolVal(*obj.Running), cty.BoolVal(info.State.Running),
+						cty.Path(nil).GetAttr("running"),
+					))
+				}
+				if obj.HealthStatus != nil && *obj.HealthStatus != gotHealth {
+					diags = diags.Append(dockerAssertionFailure(
+						fmt.Sprintf("%s has expected health status", subject),
+						cty.StringVal(*obj.HealthStatus), cty.StringVal(gotHealth),
+						cty.Path(nil).GetAttr("health_status"),
+					))
+				}
+				obj.Status = &info.State.Status
+				if obj.Running == nil {
+					obj.Running = &info.State.Running
+				}
+				if obj.HealthStatus == nil {
+					obj.HealthStatus = &gotHealth
+				}
+			}
+
+			if obj.ExposedPorts != nil {
+				exposed := make(map[string]bool, len(gotPorts))
+				for _, port := range gotPorts {
+					exposed[port] = true
+				}
+				for _, port := range obj.ExposedPorts {
+					if client.failedFast(diags) {
+						break
+					}
+					if !exposed[port] {
+						diags = diags.Append(dockerAssertionFailure(
+							fmt.Sprintf("%s exposes port %s", subject, port),
+							cty.StringVal(port), dockerStringsVal(gotPorts),
+							cty.Path(nil).GetAttr("exposed_ports"),
+						))
+					}
+				}
+			}
+
+			if obj.Labels != nil {
+				keys := make([]string, 0, len(obj.Labels))
+				for k := range obj.Labels {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					if client.failedFast(diags) {
+						break
+					}
+					want := obj.Labels[k]
+					got, ok := gotLabels[k]
+					if !ok || got != want {
+						gotVal := cty.NullVal(cty.String)
+						if ok {
+							gotVal = cty.StringVal(got)
+						}
+						diags = diags.Append(dockerAssertionFailure(
+							fmt.Sprintf("%s has expected label %q", subject, k),
+							cty.StringVal(want), gotVal,
+							cty.Path(nil).GetAttr("labels").Index(cty.StringVal(k)),
+						))
+					}
+				}
+			}
+
+			// Arguments that were set in the configuration must be returned
+			// unchanged, so we only populate those that were left unset.
+			obj.ID = &info.ID
+			if obj.ExposedPorts == nil {
+				obj.ExposedPorts = gotPorts
+			}
+			if obj.Labels == nil {
+				obj.Labels = gotLabels
+			}
+			return obj, diags
+		},
+	})
+}
+
+// dockerHTTPClient returns an HTTP client and base URL for talking to the
+// Docker Engine API at the given host, which uses the same syntax as the
+// DOCKER_HOST environment variable, like "unix:///var/run/docker.sock" or
+// "tcp://127.0.0.1:2375".
+func dockerHTTPClient(host string) (*http.Client, string, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, "", err
+	}
+	switch u.Scheme {
+	case "unix":
+		sockPath := u.Path
+		return &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", sockPath)
+				},
+			},
+		}, "http://docker", nil
+	case "tcp", "http":
+		return http.DefaultClient, "http://" + u.Host, nil
+	case "https":
+		return http.DefaultClient, "https://" + u.Host, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported scheme %q; must be unix, tcp, http, or https", u.Scheme)
+	}
+}
+
+func dockerAssertionFailure(statement string, want, got cty.Value, path cty.Path) tfsdk.Diagnostic {
+	return tfsdk.Diagnostic{
+		Severity: tfsdk.Error,
+		Summary:  "Test failure",
+		Detail:   fmt.Sprintf("Assertion failed: %s.\n  Want: %s\n  Got:  %s", statement, formatValue(want, 2), formatValue(got, 2)),
+		Path:     path,
+	}
+}
+
+func dockerStringsVal(strs []string) cty.Value {
+	if len(strs) == 0 {
+		return cty.ListValEmpty(cty.String)
+	}
+	vals := make([]cty.Value, len(strs))
+	for i, s := range strs {
+		vals[i] = cty.StringVal(s)
+	}
+	return cty.ListVal(vals)
+}
@@ -0,0 +1,164 @@
+package testing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDRTDocker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-testing-docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sockPath := filepath.Join(dir, "docker.sock")
+	ln, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/web/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"Id": "abc123",
+			"State": {"Status": "running", "Running": true, "Health": {"Status": "healthy"}},
+			"Config": {
+				"Labels": {"app": "web", "tier": "frontend"},
+				"ExposedPorts": {"80/tcp": {}, "443/tcp": {}}
+			}
+		}`))
+	})
+	mux.HandleFunc("/images/nginx:latest/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"Id": "sha256:def456",
+			"Config": {"Labels": null, "ExposedPorts": {"80/tcp": {}}}
+		}`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "No such object"}`))
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(ln)
+	defer server.Close()
+	host := "unix://" + sockPath
+
+	t.Run("container pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_docker" "test" {
+  host      = %q
+  container = "web"
+
+  running       = true
+  health_status = "healthy"
+  exposed_ports = ["80/tcp"]
+  labels = {
+    app = "web"
+  }
+}
+
+data "testing_assertions" "test" {
+  equal "id" {
+	got  = data.testing_docker.test.id
+	want = "abc123"
+  }
+  equal "status" {
+	got  = data.testing_docker.test.status
+	want = "running"
+  }
+}
+`, host))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("image pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_docker" "test" {
+  host  = %q
+  image = "nginx:latest"
+}
+
+data "testing_assertions" "test" {
+  equal "exposed_ports" {
+	got  = data.testing_docker.test.exposed_ports
+	want = tolist(["80/tcp"])
+  }
+  equal "labels" {
+	got  = length(data.testing_docker.test.labels)
+	want = 0
+  }
+}
+`, host))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("label fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_docker" "test" {
+  host      = %q
+  container = "web"
+  labels = {
+    tier = "backend"
+  }
+}
+`, host))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("not running", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_docker" "test" {
+  host      = %q
+  container = "web"
+  running   = false
+}
+`, host))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("missing container", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_docker" "test" {
+  host      = %q
+  container = "nonexistent"
+}
+`, host))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
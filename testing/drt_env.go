@@ -0,0 +1,145 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+type envDRT struct {
+	Subject   *string   `cty:"subject"`
+	Variables cty.Value `cty:"variable"`
+
+	Values          map[string]string `cty:"values"`
+	SensitiveValues map[string]string `cty:"sensitive_values"`
+}
+
+type envDRTVariable struct {
+	Required  *bool   `cty:"required"`
+	Equal     *string `cty:"equal"`
+	Pattern   *string `cty:"pattern"`
+	Sensitive *bool   `cty:"sensitive"`
+	Statement *string `cty:"statement"`
+}
+
+func envDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_env", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"subject": {Type: cty.String, Optional: true},
+
+				"values":           {Type: cty.Map(cty.String), Computed: true},
+				"sensitive_values": {Type: cty.Map(cty.String), Computed: true, Sensitive: true},
+			},
+			NestedBlockTypes: map[string]*tfschema.NestedBlockType{
+				"variable": {
+					Nesting: tfschema.NestingMap,
+					Content: tfschema.BlockType{
+						Attributes: map[string]*tfschema.Attribute{
+							"required":  {Type: cty.Bool, Optional: true},
+							"equal":     {Type: cty.String, Optional: true},
+							"pattern":   {Type: cty.String, Optional: true, ValidateFn: validateRegexp},
+							"sensitive": {Type: cty.Bool, Optional: true},
+							"statement": {Type: cty.String, Optional: true},
+						},
+					},
+				},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *envDRT) (*envDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			subject := ""
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+
+			obj.Values = make(map[string]string)
+			obj.SensitiveValues = make(map[string]string)
+			for it := obj.Variables.ElementIterator(); it.Next(); {
+				if client.failedFast(diags) {
+					break
+				}
+				k, v := it.Element()
+				var variable envDRTVariable
+				err := gocty.FromCtyValue(v, &variable)
+				if err != nil {
+					// Should never happen; indicates that our struct is wrong.
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Bug in 'testing' provider",
+						Detail:   fmt.Sprintf("The provider encountered a problem while decoding the variable %q block: %s.\n\nThis is a bug in the provider; please report it in the provider's issue tracker.", k.AsString(), err),
+					})
+					continue
+				}
+				name := k.AsString()
+				path := cty.Path(nil).GetAttr("variable").Index(k)
+				sensitive := variable.Sensitive != nil && *variable.Sensitive
+
+				statement := fmt.Sprintf("environment variable %s is set correctly", name)
+				if variable.Statement != nil {
+					statement = *variable.Statement
+				}
+				if subject != "" {
+					statement = fmt.Sprintf("%s %s", subject, statement)
+				}
+
+				val, exists := os.LookupEnv(name)
+				if !exists {
+					if variable.Required == nil || *variable.Required {
+						diags = diags.Append(tfsdk.Diagnostic{
+							Severity: tfsdk.Error,
+							Summary:  "Test failure",
+							Detail:   fmt.Sprintf("Assertion failed: %s.\n\nThe environment variable %s is not set.", statement, name),
+							Path:     path,
+						})
+					}
+					continue
+				}
+				if sensitive {
+					obj.SensitiveValues[name] = val
+				} else {
+					obj.Values[name] = val
+				}
+
+				if variable.Equal != nil && val != *variable.Equal {
+					detail := fmt.Sprintf("Assertion failed: %s.\n\nThe environment variable %s does not have the expected value.", statement, name)
+					if !sensitive {
+						detail = namedAssertionFailureMsg(statement, "value", cty.StringVal(*variable.Equal), cty.StringVal(val))
+					}
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   detail,
+						Path:     path.GetAttr("equal"),
+					})
+				}
+				if variable.Pattern != nil {
+					// The pattern was already checked by validateRegexp.
+					re := regexp.MustCompile(*variable.Pattern)
+					if !re.MatchString(val) {
+						detail := fmt.Sprintf("Assertion failed: %s.\n\nThe value of environment variable %s does not match the pattern %s.", statement, name, formatValue(cty.StringVal(*variable.Pattern), 2))
+						if !sensitive {
+							detail = fmt.Sprintf("Assertion failed: %s.\n  Want value matching: %s\n  Got value:           %s", statement, formatValue(cty.StringVal(*variable.Pattern), 2), formatValue(cty.StringVal(val), 2))
+						}
+						diags = diags.Append(tfsdk.Diagnostic{
+							Severity: tfsdk.Error,
+							Summary:  "Test failure",
+							Detail:   detail,
+							Path:     path.GetAttr("pattern"),
+						})
+					}
+				}
+			}
+
+			return obj, diags
+		},
+	})
+}
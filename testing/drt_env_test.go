@@ -0,0 +1,83 @@
+package testing
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDRTEnv(t *testing.T) {
+	os.Setenv("TF_TESTING_ENV_REGION", "us-west-2")
+	os.Setenv("TF_TESTING_ENV_TOKEN", "s3cr3t")
+	defer os.Unsetenv("TF_TESTING_ENV_REGION")
+	defer os.Unsetenv("TF_TESTING_ENV_TOKEN")
+
+	t.Run("pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_env" "test" {
+  variable "TF_TESTING_ENV_REGION" {
+    pattern = "^us-"
+  }
+  variable "TF_TESTING_ENV_TOKEN" {
+    equal     = "s3cr3t"
+    sensitive = true
+  }
+  variable "TF_TESTING_ENV_UNSET" {
+    required = false
+  }
+}
+
+data "testing_assertions" "test" {
+  equal "region" {
+    got  = data.testing_env.test.values["TF_TESTING_ENV_REGION"]
+    want = "us-west-2"
+  }
+  check "token_not_in_values" {
+    expect = !contains(keys(data.testing_env.test.values), "TF_TESTING_ENV_TOKEN")
+  }
+  check "token" {
+    expect = data.testing_env.test.sensitive_values["TF_TESTING_ENV_TOKEN"] == "s3cr3t"
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("missing", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_env" "test" {
+  variable "TF_TESTING_ENV_UNSET" {}
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("pattern fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_env" "test" {
+  variable "TF_TESTING_ENV_REGION" {
+    pattern = "^eu-"
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
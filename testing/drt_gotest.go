@@ -0,0 +1,248 @@
+package testing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type gotestDRT struct {
+	Program     []string          `cty:"program"`
+	Environment map[string]string `cty:"environment"`
+	Dir         *string           `cty:"dir"`
+	Input       *string           `cty:"input"`
+
+	Results []gotestDRTResult `cty:"results"`
+	Passed  *int              `cty:"passed"`
+	Failed  *int              `cty:"failed"`
+	Skipped *int              `cty:"skipped"`
+}
+
+type gotestDRTResult struct {
+	Package string  `cty:"package"`
+	Test    string  `cty:"test"`
+	Action  string  `cty:"action"`
+	Elapsed float64 `cty:"elapsed"`
+	Output  string  `cty:"output"`
+}
+
+var gotestResultType = cty.Object(map[string]cty.Type{
+	"package": cty.String,
+	"test":    cty.String,
+	"action":  cty.String,
+	"elapsed": cty.Number,
+	"output":  cty.String,
+})
+
+func gotestDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_gotest", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"program": {
+					Type:       cty.List(cty.String),
+					Optional:   true,
+					ValidateFn: validateProgram,
+				},
+				"environment": {
+					Type:     cty.Map(cty.String),
+					Optional: true,
+				},
+				"dir":   {Type: cty.String, Optional: true},
+				"input": {Type: cty.String, Optional: true},
+
+				"results": {Type: cty.List(gotestResultType), Computed: true},
+				"passed":  {Type: cty.Number, Computed: true},
+				"failed":  {Type: cty.Number, Computed: true},
+				"skipped": {Type: cty.Number, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *gotestDRT) (*gotestDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			if (obj.Program == nil) == (obj.Input == nil) {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid go test configuration",
+					Detail:   "Exactly one of the \"program\" and \"input\" arguments must be set, to specify either a program to run or existing output to read.",
+				})
+				return obj, diags
+			}
+
+			var stream io.Reader
+			var runErr error
+			stderrForOutput := ""
+			if obj.Program != nil {
+				cmd := programCommand(ctx, client, obj.Program, obj.Environment)
+				if obj.Dir != nil {
+					cmd.Dir = *obj.Dir
+				}
+				var outBuf, errBuf bytes.Buffer
+				cmd.Stdout = &outBuf
+				cmd.Stderr = &errBuf
+
+				// "go test" exits with a non-zero status if any tests fail, so
+				// we'll only treat that as an error if it doesn't also produce
+				// any test events that would explain the failure.
+				release, err := client.acquireProgram(ctx)
+				if err != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test program cancelled",
+						Detail:   fmt.Sprintf("The test program was not started: %s.", err),
+					})
+					return obj, diags
+				}
+				runErr = cmd.Run()
+				release()
+				stream = &outBuf
+
+				stderrForOutput = strings.Replace(strings.TrimSpace(errBuf.String()), "\n", "\n  ", -1)
+				if stderrForOutput != "" {
+					stderrForOutput = "\n\nThe test program produced the following error messages:\n  " + stderrForOutput
+				}
+			} else {
+				stream = strings.NewReader(*obj.Input)
+			}
+
+			results, err := readGoTestEvents(stream)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test program failed",
+					Detail:   fmt.Sprintf("Error reading go test output: %s.%s", err, stderrForOutput),
+				})
+				return obj, diags
+			}
+			if len(results) == 0 && runErr != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test program failed",
+					Detail:   fmt.Sprintf("Error running test program: %s.%s", runErr, stderrForOutput),
+				})
+				return obj, diags
+			}
+
+			failedTestsByPkg := make(map[string]int)
+			var passed, failed, skipped int
+			for _, result := range results {
+				if result.Test == "" {
+					continue
+				}
+				switch result.Action {
+				case "pass":
+					passed++
+				case "fail":
+					failed++
+					failedTestsByPkg[result.Package]++
+				case "skip":
+					skipped++
+				}
+			}
+
+			for _, result := range results {
+				if client.failedFast(diags) {
+					break
+				}
+				if result.Action != "fail" {
+					continue
+				}
+				testOutput := ""
+				if output := strings.TrimRight(result.Output, "\n"); output != "" {
+					testOutput = "\n\nOutput from test:\n  " + strings.Replace(output, "\n", "\n  ", -1)
+				}
+				if result.Test != "" {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   fmt.Sprintf("Test failed: %s in package %s.%s", result.Test, result.Package, testOutput),
+					})
+				} else if failedTestsByPkg[result.Package] == 0 {
+					// A package can fail without any of its tests failing if,
+					// for example, it fails to compile or a test panics.
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   fmt.Sprintf("Package failed: %s.%s", result.Package, testOutput),
+					})
+				}
+			}
+
+			obj.Results = results
+			obj.Passed = &passed
+			obj.Failed = &failed
+			obj.Skipped = &skipped
+			return obj, diags
+		},
+	})
+}
+
+// goTestEvent is the structure of each line of output from "go test -json".
+type goTestEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// readGoTestEvents reads a stream of "go test -json" events and summarizes
+// them as one result for each package and each test that completed, in the
+// order that they completed.
+//
+// Lines that are not JSON objects are ignored, because "go test" may emit
+// non-JSON messages, such as build errors, alongside its events.
+func readGoTestEvents(r io.Reader) ([]gotestDRTResult, error) {
+	type key struct{ pkg, test string }
+	outputs := make(map[key]*strings.Builder)
+	results := []gotestDRTResult{}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 16*1024*1024)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var ev goTestEvent
+		if err := json.Unmarshal(line, &ev); err != nil {
+			return nil, err
+		}
+		k := key{ev.Package, ev.Test}
+
+		switch ev.Action {
+		case "output":
+			buf, ok := outputs[k]
+			if !ok {
+				buf = &strings.Builder{}
+				outputs[k] = buf
+			}
+			buf.WriteString(ev.Output)
+		case "pass", "fail", "skip":
+			output := ""
+			if buf, ok := outputs[k]; ok {
+				output = buf.String()
+				delete(outputs, k)
+			}
+			results = append(results, gotestDRTResult{
+				Package: ev.Package,
+				Test:    ev.Test,
+				Action:  ev.Action,
+				Elapsed: ev.Elapsed,
+				Output:  output,
+			})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,57 @@
+package testing
+
+import "testing"
+
+func TestDRTGoTest(t *testing.T) {
+	t.Run("pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_gotest" "test" {
+  input = <<EOT
+{"Action":"run","Package":"example.com/foo","Test":"TestA"}
+{"Action":"output","Package":"example.com/foo","Test":"TestA","Output":"=== RUN   TestA\n"}
+{"Action":"pass","Package":"example.com/foo","Test":"TestA","Elapsed":0.01}
+{"Action":"run","Package":"example.com/foo","Test":"TestB"}
+{"Action":"skip","Package":"example.com/foo","Test":"TestB","Elapsed":0}
+{"Action":"pass","Package":"example.com/foo","Elapsed":0.02}
+EOT
+}
+
+data "testing_assertions" "test" {
+  equal "counts" {
+	got  = [
+	  data.testing_gotest.test.passed,
+	  data.testing_gotest.test.failed,
+	  data.testing_gotest.test.skipped,
+	]
+	want = [1, 0, 1]
+  }
+  equal "first" {
+	got  = data.testing_gotest.test.results[0].output
+	want = "=== RUN   TestA\n"
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_gotest" "test" {
+  program = ["echo", "{\"Action\":\"fail\",\"Package\":\"example.com/foo\",\"Test\":\"TestA\"}"]
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
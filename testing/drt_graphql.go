@@ -0,0 +1,218 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+type graphqlDRT struct {
+	URL           string            `cty:"url"`
+	Query         string            `cty:"query"`
+	OperationName *string           `cty:"operation_name"`
+	Variables     cty.Value         `cty:"variables"`
+	Headers       map[string]string `cty:"headers"`
+	Subject       *string           `cty:"subject"`
+	Statement     *string           `cty:"statement"`
+
+	WantData     cty.Value `cty:"want_data"`
+	ExpectErrors *bool     `cty:"expect_errors"`
+
+	Data   cty.Value `cty:"data"`
+	Errors []string  `cty:"errors"`
+}
+
+// graphqlResponse is the standard GraphQL response envelope. We keep the
+// data as raw JSON so that we can decode it directly into a cty value.
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func graphqlDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_graphql", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"url":            {Type: cty.String, Required: true},
+				"query":          {Type: cty.String, Required: true},
+				"operation_name": {Type: cty.String, Optional: true},
+				"variables":      {Type: cty.DynamicPseudoType, Optional: true},
+				"headers":        {Type: cty.Map(cty.String), Optional: true},
+				"subject":        {Type: cty.String, Optional: true},
+				"statement":      {Type: cty.String, Optional: true},
+
+				"want_data":     {Type: cty.DynamicPseudoType, Optional: true},
+				"expect_errors": {Type: cty.Bool, Optional: true},
+
+				"data":   {Type: cty.DynamicPseudoType, Computed: true},
+				"errors": {Type: cty.List(cty.String), Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *graphqlDRT) (*graphqlDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			reqBody := map[string]interface{}{
+				"query": obj.Query,
+			}
+			if obj.OperationName != nil {
+				reqBody["operationName"] = *obj.OperationName
+			}
+			if !obj.Variables.IsNull() {
+				vars, err := ctyjson.Marshal(obj.Variables, obj.Variables.Type())
+				if err != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Invalid variables",
+						Detail:   fmt.Sprintf("Cannot encode the given variables as JSON: %s.", tfsdk.FormatError(err)),
+						Path:     cty.Path(nil).GetAttr("variables"),
+					})
+					return obj, diags
+				}
+				reqBody["variables"] = json.RawMessage(vars)
+			}
+
+			resp, err := graphqlRequest(ctx, obj.URL, obj.Headers, reqBody)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "GraphQL request failed",
+					Detail:   fmt.Sprintf("Error making GraphQL request to %s: %s.", obj.URL, err),
+					Path:     cty.Path(nil).GetAttr("url"),
+				})
+				return obj, diags
+			}
+
+			obj.Data = cty.NullVal(cty.DynamicPseudoType)
+			if len(resp.Data) != 0 && string(resp.Data) != "null" {
+				ty, err := ctyjson.ImpliedType(resp.Data)
+				if err == nil {
+					obj.Data, err = ctyjson.Unmarshal(resp.Data, ty)
+				}
+				if err != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Invalid GraphQL response",
+						Detail:   fmt.Sprintf("The server returned data that cannot be decoded: %s.", tfsdk.FormatError(err)),
+						Path:     cty.Path(nil).GetAttr("url"),
+					})
+					return obj, diags
+				}
+			}
+			obj.Errors = make([]string, len(resp.Errors))
+			for i, e := range resp.Errors {
+				obj.Errors[i] = e.Message
+			}
+
+			subject := ""
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+			statement := func(def string) string {
+				s := def
+				if obj.Statement != nil {
+					s = *obj.Statement
+				}
+				if subject != "" {
+					s = fmt.Sprintf("%s %s", subject, s)
+				}
+				return s
+			}
+
+			expectErrors := obj.ExpectErrors != nil && *obj.ExpectErrors
+			switch {
+			case !expectErrors && len(obj.Errors) != 0:
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   fmt.Sprintf("Assertion failed: %s.\n\nThe server returned the following GraphQL errors:\n  - %s", statement("query succeeds"), strings.Join(obj.Errors, "\n  - ")),
+					Path:     cty.Path(nil).GetAttr("query"),
+				})
+			case expectErrors && len(obj.Errors) == 0:
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   fmt.Sprintf("Assertion failed: %s.\n\nThe server did not return any GraphQL errors.", statement("query fails")),
+					Path:     cty.Path(nil).GetAttr("expect_errors"),
+				})
+			}
+
+			if !obj.WantData.IsNull() && !obj.Data.RawEquals(obj.WantData) {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail: fmt.Sprintf(
+						"Assertion failed: %s.\n  Want: %s\n  Got:  %s",
+						statement("query returns the expected data"),
+						formatValue(obj.WantData, 2),
+						formatValue(obj.Data, 2),
+					),
+					Path: cty.Path(nil).GetAttr("want_data"),
+				})
+			}
+
+			return obj, diags
+		},
+	})
+}
+
+// graphqlRequest sends the given GraphQL request body to the given endpoint
+// and decodes the response envelope.
+//
+// GraphQL servers often report request errors using a non-success status
+// code along with a normal response body, so we accept any status code as
+// long as the body is a valid GraphQL response.
+func graphqlRequest(ctx context.Context, url string, headers map[string]string, body map[string]interface{}) (*graphqlResponse, error) {
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		req.Header.Set(name, headers[name])
+	}
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret graphqlResponse
+	if err := json.Unmarshal(respBody, &ret); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("server returned %s", resp.Status)
+		}
+		return nil, fmt.Errorf("response is not valid JSON: %s", err)
+	}
+	if len(ret.Data) == 0 && ret.Errors == nil {
+		return nil, fmt.Errorf("server returned %s without a GraphQL response body", resp.Status)
+	}
+	return &ret, nil
+}
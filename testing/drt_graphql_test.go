@@ -0,0 +1,139 @@
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDRTGraphQL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(req.Query, "user"):
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"user": map[string]interface{}{
+						"id":   req.Variables["id"],
+						"name": "Alice",
+					},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":[{"message":"Cannot query field \"nope\" on type \"Query\"."}]}`))
+		}
+	}))
+	defer server.Close()
+
+	t.Run("data pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_graphql" "test" {
+  url   = %q
+  query = "query ($id: ID!) { user(id: $id) { id name } }"
+  variables = {
+    id = "u1"
+  }
+
+  want_data = {
+    user = {
+      id   = "u1"
+      name = "Alice"
+    }
+  }
+}
+
+data "testing_assertions" "test" {
+  equal "name" {
+    got  = data.testing_graphql.test.data.user.name
+    want = "Alice"
+  }
+  check "no_errors" {
+    expect = length(data.testing_graphql.test.errors) == 0
+  }
+}
+`, server.URL))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("data fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_graphql" "test" {
+  url   = %q
+  query = "query ($id: ID!) { user(id: $id) { id name } }"
+  variables = {
+    id = "u1"
+  }
+
+  want_data = {
+    user = {
+      id   = "u1"
+      name = "Bob"
+    }
+  }
+}
+`, server.URL))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("errors fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_graphql" "test" {
+  url   = %q
+  query = "{ nope }"
+}
+`, server.URL))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("expect errors", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_graphql" "test" {
+  url           = %q
+  query         = "{ nope }"
+  expect_errors = true
+}
+
+data "testing_assertions" "test" {
+  check "error_message" {
+    expect = length(regexall("nope", data.testing_graphql.test.errors[0])) > 0
+  }
+}
+`, server.URL))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+}
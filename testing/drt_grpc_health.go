@@ -0,0 +1,174 @@
+package testing
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type grpcHealthDRT struct {
+	Address string  `cty:"address"`
+	Service *string `cty:"service"`
+	Timeout *string `cty:"timeout"`
+
+	TLS                *bool   `cty:"tls"`
+	TLSServerName      *string `cty:"tls_server_name"`
+	CACertificatePEM   *string `cty:"ca_certificate_pem"`
+	InsecureSkipVerify *bool   `cty:"insecure_skip_verify"`
+
+	Status *string `cty:"status"`
+}
+
+func grpcHealthDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_grpc_health", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"address": {Type: cty.String, Required: true},
+				"service": {Type: cty.String, Optional: true},
+				"timeout": {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+
+				"tls":                  {Type: cty.Bool, Optional: true},
+				"tls_server_name":      {Type: cty.String, Optional: true},
+				"ca_certificate_pem":   {Type: cty.String, Optional: true},
+				"insecure_skip_verify": {Type: cty.Bool, Optional: true},
+
+				"status": {Type: cty.String, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *grpcHealthDRT) (*grpcHealthDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			service := ""
+			if obj.Service != nil {
+				service = *obj.Service
+			}
+			timeout := client.timeout(obj.Timeout, 30*time.Second)
+
+			useTLS := obj.TLS != nil && *obj.TLS
+			if !useTLS {
+				// Otherwise someone who sets a CA certificate, say, would
+				// unknowingly be testing a plaintext connection.
+				for _, arg := range []struct {
+					name string
+					set  bool
+				}{
+					{"tls_server_name", obj.TLSServerName != nil},
+					{"ca_certificate_pem", obj.CACertificatePEM != nil},
+					{"insecure_skip_verify", obj.InsecureSkipVerify != nil},
+				} {
+					if arg.set {
+						diags = diags.Append(tfsdk.Diagnostic{
+							Severity: tfsdk.Error,
+							Summary:  "Invalid TLS configuration",
+							Detail:   fmt.Sprintf("The %q argument applies only to TLS connections, so it requires tls = true.", arg.name),
+							Path:     cty.Path(nil).GetAttr(arg.name),
+						})
+					}
+				}
+				if diags.HasErrors() {
+					return obj, diags
+				}
+			}
+
+			var dialOpts []grpc.DialOption
+			if useTLS {
+				tlsConfig := &tls.Config{}
+				if obj.TLSServerName != nil {
+					tlsConfig.ServerName = *obj.TLSServerName
+				}
+				if obj.InsecureSkipVerify != nil {
+					tlsConfig.InsecureSkipVerify = *obj.InsecureSkipVerify
+				}
+				if obj.CACertificatePEM != nil {
+					pool := x509.NewCertPool()
+					if !pool.AppendCertsFromPEM([]byte(*obj.CACertificatePEM)) {
+						diags = diags.Append(tfsdk.Diagnostic{
+							Severity: tfsdk.Error,
+							Summary:  "Invalid CA certificate",
+							Detail:   "The given CA certificate does not contain any valid PEM-encoded certificates.",
+							Path:     cty.Path(nil).GetAttr("ca_certificate_pem"),
+						})
+						return obj, diags
+					}
+					tlsConfig.RootCAs = pool
+				}
+				dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+			} else {
+				dialOpts = append(dialOpts, grpc.WithInsecure())
+			}
+
+			deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			conn, err := grpc.DialContext(deadlineCtx, obj.Address, dialOpts...)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Failed to connect",
+					Detail:   fmt.Sprintf("Failed to prepare a gRPC connection to %s: %s.", obj.Address, err),
+					Path:     cty.Path(nil).GetAttr("address"),
+				})
+				return obj, diags
+			}
+			defer conn.Close()
+
+			healthClient := healthpb.NewHealthClient(conn)
+			var status healthpb.HealthCheckResponse_ServingStatus
+			var lastErr error
+			for {
+				resp, err := healthClient.Check(deadlineCtx, &healthpb.HealthCheckRequest{
+					Service: service,
+				})
+				if err == nil {
+					status = resp.Status
+					lastErr = nil
+					if status == healthpb.HealthCheckResponse_SERVING {
+						break
+					}
+				} else if deadlineCtx.Err() == nil || lastErr == nil {
+					lastErr = err
+				}
+
+				select {
+				case <-time.After(1 * time.Second):
+				case <-deadlineCtx.Done():
+				}
+				if deadlineCtx.Err() != nil {
+					break
+				}
+			}
+
+			if status != healthpb.HealthCheckResponse_SERVING {
+				serviceDesc := "the server"
+				if service != "" {
+					serviceDesc = fmt.Sprintf("service %q", service)
+				}
+				var detail string
+				if lastErr != nil {
+					detail = fmt.Sprintf("The health check for %s at %s did not succeed within %s: %s.", serviceDesc, obj.Address, timeout, lastErr)
+				} else {
+					detail = fmt.Sprintf("The health check for %s at %s still reported status %s after %s.", serviceDesc, obj.Address, status, timeout)
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   detail,
+				})
+			}
+
+			statusStr := status.String()
+			obj.Status = &statusStr
+			return obj, diags
+		},
+	}, ownTimeout)
+}
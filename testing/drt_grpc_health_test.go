@@ -0,0 +1,119 @@
+package testing
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestDRTGRPCHealth(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("ok", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("down", healthpb.HealthCheckResponse_NOT_SERVING)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(ln)
+	defer server.Stop()
+	addr := ln.Addr().String()
+
+	t.Run("server serving", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_grpc_health" "test" {
+  address = %q
+}
+
+data "testing_assertions" "test" {
+  equal "status" {
+	got  = data.testing_grpc_health.test.status
+	want = "SERVING"
+  }
+}
+`, addr))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("service serving", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_grpc_health" "test" {
+  address = %q
+  service = "ok"
+}
+`, addr))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("service not serving", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_grpc_health" "test" {
+  address = %q
+  service = "down"
+  timeout = "500ms"
+}
+`, addr))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("unknown service", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_grpc_health" "test" {
+  address = %q
+  service = "nonexistent"
+  timeout = "500ms"
+}
+`, addr))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("TLS arguments without tls", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_grpc_health" "test" {
+  address            = %q
+  ca_certificate_pem = "not checked"
+}
+`, addr))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Fatal("succeeded; want error")
+		}
+		if !strings.Contains(err.Error(), "Invalid TLS configuration") {
+			t.Errorf("error does not reject the TLS arguments\n%s", err)
+		}
+	})
+}
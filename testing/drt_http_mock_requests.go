@@ -0,0 +1,211 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+type httpMockRequestsDRT struct {
+	RequestLog string    `cty:"request_log"`
+	Subject    *string   `cty:"subject"`
+	Expects    cty.Value `cty:"expect"`
+
+	Requests    []httpMockRequest `cty:"requests"`
+	MatchCounts map[string]int    `cty:"match_counts"`
+}
+
+type httpMockRequestsDRTExpect struct {
+	Method      *string `cty:"method"`
+	Path        *string `cty:"path"`
+	PathPattern *string `cty:"path_pattern"`
+	BodyPattern *string `cty:"body_pattern"`
+	Count       *int    `cty:"count"`
+	Statement   *string `cty:"statement"`
+}
+
+type httpMockRequest struct {
+	Method  string            `cty:"method"`
+	Path    string            `cty:"path"`
+	Query   string            `cty:"query"`
+	Headers map[string]string `cty:"headers"`
+	Body    string            `cty:"body"`
+}
+
+func httpMockRequestsDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_http_mock_requests", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"request_log": {Type: cty.String, Required: true},
+				"subject":     {Type: cty.String, Optional: true},
+
+				"requests": {
+					Type: cty.List(cty.Object(map[string]cty.Type{
+						"method":  cty.String,
+						"path":    cty.String,
+						"query":   cty.String,
+						"headers": cty.Map(cty.String),
+						"body":    cty.String,
+					})),
+					Computed: true,
+				},
+				"match_counts": {Type: cty.Map(cty.Number), Computed: true},
+			},
+			NestedBlockTypes: map[string]*tfschema.NestedBlockType{
+				"expect": {
+					Nesting: tfschema.NestingMap,
+					Content: tfschema.BlockType{
+						Attributes: map[string]*tfschema.Attribute{
+							"method":       {Type: cty.String, Optional: true},
+							"path":         {Type: cty.String, Optional: true},
+							"path_pattern": {Type: cty.String, Optional: true, ValidateFn: validateRegexp},
+							"body_pattern": {Type: cty.String, Optional: true, ValidateFn: validateRegexp},
+							"count":        {Type: cty.Number, Optional: true},
+							"statement":    {Type: cty.String, Optional: true},
+						},
+					},
+				},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *httpMockRequestsDRT) (*httpMockRequestsDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			records, err := readHTTPRequestLog(obj.RequestLog)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Failed to read request log",
+					Detail:   fmt.Sprintf("Error reading HTTP request log %s: %s.", obj.RequestLog, err),
+					Path:     cty.Path(nil).GetAttr("request_log"),
+				})
+				return obj, diags
+			}
+
+			obj.Requests = make([]httpMockRequest, len(records))
+			for i, rec := range records {
+				headers := rec.Headers
+				if headers == nil {
+					headers = map[string]string{}
+				}
+				obj.Requests[i] = httpMockRequest{
+					Method:  rec.Method,
+					Path:    rec.Path,
+					Query:   rec.Query,
+					Headers: headers,
+					Body:    rec.Body,
+				}
+			}
+
+			subject := ""
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+
+			obj.MatchCounts = make(map[string]int)
+			for it := obj.Expects.ElementIterator(); it.Next(); {
+				if client.failedFast(diags) {
+					break
+				}
+				k, v := it.Element()
+				var expect httpMockRequestsDRTExpect
+				err := gocty.FromCtyValue(v, &expect)
+				if err != nil {
+					// Should never happen; indicates that our struct is wrong.
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Bug in 'testing' provider",
+						Detail:   fmt.Sprintf("The provider encountered a problem while decoding the expect %q block: %s.\n\nThis is a bug in the provider; please report it in the provider's issue tracker.", k.AsString(), err),
+					})
+					continue
+				}
+				name := k.AsString()
+				path := cty.Path(nil).GetAttr("expect").Index(k)
+
+				if expect.Path != nil && expect.PathPattern != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Conflicting path arguments",
+						Detail:   "At most one of the \"path\" and \"path_pattern\" arguments may be set for each expected request.",
+						Path:     path,
+					})
+					continue
+				}
+
+				// The patterns were already checked by validateRegexp.
+				var pathRe, bodyRe *regexp.Regexp
+				if expect.PathPattern != nil {
+					pathRe = regexp.MustCompile(*expect.PathPattern)
+				}
+				if expect.BodyPattern != nil {
+					bodyRe = regexp.MustCompile(*expect.BodyPattern)
+				}
+
+				count := 0
+				for _, rec := range records {
+					switch {
+					case expect.Method != nil && !strings.EqualFold(rec.Method, *expect.Method):
+					case expect.Path != nil && rec.Path != *expect.Path:
+					case pathRe != nil && !pathRe.MatchString(rec.Path):
+					case bodyRe != nil && !bodyRe.MatchString(rec.Body):
+					default:
+						count++
+					}
+				}
+				obj.MatchCounts[name] = count
+
+				statement := fmt.Sprintf("received the expected %s requests", describeExpectedRequest(name, expect))
+				if expect.Statement != nil {
+					statement = *expect.Statement
+				}
+				if subject != "" {
+					statement = fmt.Sprintf("%s %s", subject, statement)
+				}
+
+				switch {
+				case expect.Count != nil && count != *expect.Count:
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   namedAssertionFailureMsg(statement, "count", cty.NumberIntVal(int64(*expect.Count)), cty.NumberIntVal(int64(count))),
+						Path:     path.GetAttr("count"),
+					})
+				case expect.Count == nil && count == 0:
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   fmt.Sprintf("Assertion failed: %s.\n\nNone of the %d recorded requests match.", statement, len(records)),
+						Path:     path,
+					})
+				}
+			}
+
+			return obj, diags
+		},
+	})
+}
+
+// describeExpectedRequest returns a short description of the requests that
+// an expect block matches, for use in the default assertion statement.
+func describeExpectedRequest(name string, expect httpMockRequestsDRTExpect) string {
+	var parts []string
+	if expect.Method != nil {
+		parts = append(parts, strings.ToUpper(*expect.Method))
+	}
+	switch {
+	case expect.Path != nil:
+		parts = append(parts, *expect.Path)
+	case expect.PathPattern != nil:
+		parts = append(parts, fmt.Sprintf("/%s/", *expect.PathPattern))
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("%q", name)
+	}
+	return fmt.Sprintf("%q (%s)", name, strings.Join(parts, " "))
+}
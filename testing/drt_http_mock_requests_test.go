@@ -0,0 +1,102 @@
+package testing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testHTTPRequestLog = `{"time":"2019-04-01T12:00:00Z","method":"GET","path":"/v1/status","query":"","headers":{"Accept":"application/json"},"body":""}
+{"time":"2019-04-01T12:00:01Z","method":"POST","path":"/v1/widgets","query":"dry_run=false","headers":{"Content-Type":"application/json"},"body":"{\"name\":\"a\"}"}
+{"time":"2019-04-01T12:00:02Z","method":"POST","path":"/v1/widgets","query":"","headers":{"Content-Type":"application/json"},"body":"{\"name\":\"b\"}"}
+`
+
+func TestDRTHTTPMockRequests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-testing-http-mock-requests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logPath := filepath.Join(dir, "requests.log")
+	if err := ioutil.WriteFile(logPath, []byte(testHTTPRequestLog), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_http_mock_requests" "test" {
+  request_log = %q
+
+  expect "status" {
+    method = "get"
+    path   = "/v1/status"
+  }
+  expect "create" {
+    method = "POST"
+    path   = "/v1/widgets"
+    count  = 2
+  }
+  expect "create_b" {
+    path_pattern = "^/v1/"
+    body_pattern = "\"name\":\"b\""
+    count        = 1
+  }
+  expect "no_deletes" {
+    method = "DELETE"
+    count  = 0
+  }
+}
+
+data "testing_assertions" "test" {
+  equal "query" {
+    got  = data.testing_http_mock_requests.test.requests[1].query
+    want = "dry_run=false"
+  }
+  equal "counts" {
+    got = data.testing_http_mock_requests.test.match_counts
+    want = tomap({
+      status     = 1
+      create     = 2
+      create_b   = 1
+      no_deletes = 0
+    })
+  }
+}
+`, logPath))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("fail", func(t *testing.T) {
+		for name, block := range map[string]string{
+			"missing": `method = "PUT"`,
+			"count":   "path = \"/v1/widgets\"\n    count = 1",
+		} {
+			t.Run(name, func(t *testing.T) {
+				wd := testHelper.RequireNewWorkingDir(t)
+				defer wd.Close()
+
+				wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_http_mock_requests" "test" {
+  request_log = %q
+
+  expect "test" {
+    %s
+  }
+}
+`, logPath, block))
+
+				wd.RequireInit(t)
+				err := wd.Apply()
+				if err == nil {
+					t.Error("succeeded; want error")
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/jmespath/go-jmespath"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+type jmespathDRT struct {
+	Input   cty.Value `cty:"input"`
+	Subject *string   `cty:"subject"`
+	Queries cty.Value `cty:"query"`
+
+	Results cty.Value `cty:"results"`
+}
+
+type jmespathDRTQuery struct {
+	Expression string    `cty:"expression"`
+	Want       cty.Value `cty:"want"`
+	Statement  *string   `cty:"statement"`
+}
+
+func jmespathDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_jmespath", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"input":   {Type: cty.DynamicPseudoType, Required: true},
+				"subject": {Type: cty.String, Optional: true},
+
+				"results": {Type: cty.DynamicPseudoType, Computed: true},
+			},
+			NestedBlockTypes: map[string]*tfschema.NestedBlockType{
+				"query": {
+					Nesting: tfschema.NestingMap,
+					Content: tfschema.BlockType{
+						Attributes: map[string]*tfschema.Attribute{
+							"expression": {
+								Type:     cty.String,
+								Required: true,
+								ValidateFn: func(v string) tfsdk.Diagnostics {
+									var diags tfsdk.Diagnostics
+									if _, err := jmespath.Compile(v); err != nil {
+										diags = diags.Append(tfsdk.ValidationError(
+											cty.Path(nil).NewErrorf("must be a valid JMESPath expression: %s", err),
+										))
+									}
+									return diags
+								},
+							},
+							"want":      {Type: cty.DynamicPseudoType, Optional: true},
+							"statement": {Type: cty.String, Optional: true},
+						},
+					},
+				},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *jmespathDRT) (*jmespathDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			// JMESPath is defined in terms of JSON, so we round-trip the
+			// input through JSON to get the representation it expects.
+			var input interface{}
+			buf, err := ctyjson.Marshal(obj.Input, obj.Input.Type())
+			if err == nil {
+				err = json.Unmarshal(buf, &input)
+			}
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid input",
+					Detail:   fmt.Sprintf("The input value cannot be queried: %s.", tfsdk.FormatError(err)),
+					Path:     cty.Path(nil).GetAttr("input"),
+				})
+				return obj, diags
+			}
+
+			subject := ""
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+
+			results := make(map[string]cty.Value)
+			for it := obj.Queries.ElementIterator(); it.Next(); {
+				k, v := it.Element()
+				var query jmespathDRTQuery
+				err := gocty.FromCtyValue(v, &query)
+				if err != nil {
+					// Should never happen; indicates that our struct is wrong.
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Bug in 'testing' provider",
+						Detail:   fmt.Sprintf("The provider encountered a problem while decoding the query %q block: %s.\n\nThis is a bug in the provider; please report it in the provider's issue tracker.", k.AsString(), err),
+					})
+					continue
+				}
+				path := cty.Path(nil).GetAttr("query").Index(k)
+
+				result, err := jmespathQuery(query.Expression, input)
+				if err != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Query failed",
+						Detail:   fmt.Sprintf("Error evaluating JMESPath expression: %s.", err),
+						Path:     path.GetAttr("expression"),
+					})
+					continue
+				}
+				results[k.AsString()] = result
+
+				if query.Want.IsNull() || result.RawEquals(query.Want) {
+					continue
+				}
+				statement := ""
+				if query.Statement != nil {
+					if subject != "" {
+						statement = fmt.Sprintf("%s %s", subject, *query.Statement)
+					} else {
+						statement = *query.Statement
+					}
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail: fmt.Sprintf(
+						"Assertion failed: %s.\n  Query: %s\n  Want:  %s\n  Got:   %s",
+						statement,
+						query.Expression,
+						formatValue(query.Want, 2),
+						formatValue(result, 2),
+					),
+					Path: path.GetAttr("want"),
+				})
+			}
+			obj.Results = cty.ObjectVal(results)
+
+			return obj, diags
+		},
+	})
+}
+
+// jmespathQuery evaluates the given JMESPath expression against the given
+// JSON-like value and returns the result as a cty value, decoded in the same
+// way as Terraform's jsondecode function.
+func jmespathQuery(expr string, input interface{}) (cty.Value, error) {
+	raw, err := jmespath.Search(expr, input)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	if raw == nil {
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	}
+	buf, err := json.Marshal(raw)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	ty, err := ctyjson.ImpliedType(buf)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return ctyjson.Unmarshal(buf, ty)
+}
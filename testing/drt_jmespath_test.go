@@ -0,0 +1,97 @@
+package testing
+
+import (
+	"testing"
+)
+
+func TestDRTJMESPath(t *testing.T) {
+	t.Run("pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+locals {
+  cluster = {
+    name = "example"
+    nodes = [
+      { name = "a", zone = "us-east-1a", ready = true },
+      { name = "b", zone = "us-east-1b", ready = true },
+      { name = "c", zone = "us-east-1a", ready = false },
+    ]
+  }
+}
+
+data "testing_jmespath" "test" {
+  input = local.cluster
+
+  query "ready_nodes" {
+    expression = "nodes[?ready].name"
+    want       = ["a", "b"]
+  }
+  query "zone_a_count" {
+    expression = "length(nodes[?zone == 'us-east-1a'])"
+    want       = 2
+  }
+  query "missing" {
+    expression = "nothing.here"
+  }
+}
+
+data "testing_assertions" "test" {
+  equal "name" {
+    got  = data.testing_jmespath.test.results.ready_nodes[0]
+    want = "a"
+  }
+  check "missing" {
+    expect = data.testing_jmespath.test.results.missing == null
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_jmespath" "test" {
+  input = {
+    nodes = [{ name = "a" }, { name = "b" }]
+  }
+
+  query "names" {
+    expression = "nodes[*].name"
+    want       = ["a"]
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("invalid expression", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_jmespath" "test" {
+  input = {}
+
+  query "bad" {
+    expression = "nodes[?"
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
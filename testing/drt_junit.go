@@ -0,0 +1,263 @@
+package testing
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type junitDRT struct {
+	Paths []string `cty:"paths"`
+
+	Results  []junitDRTResult `cty:"results"`
+	Tests    *int             `cty:"tests"`
+	Failures *int             `cty:"failures"`
+	Errors   *int             `cty:"errors"`
+	Skipped  *int             `cty:"skipped"`
+}
+
+type junitDRTResult struct {
+	Suite     string  `cty:"suite"`
+	ClassName string  `cty:"classname"`
+	Name      string  `cty:"name"`
+	Status    string  `cty:"status"`
+	Message   string  `cty:"message"`
+	Time      float64 `cty:"time"`
+}
+
+var junitResultType = cty.Object(map[string]cty.Type{
+	"suite":     cty.String,
+	"classname": cty.String,
+	"name":      cty.String,
+	"status":    cty.String,
+	"message":   cty.String,
+	"time":      cty.Number,
+})
+
+func junitDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_junit", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"paths": {
+					Type:     cty.List(cty.String),
+					Required: true,
+					ValidateFn: func(v []string) tfsdk.Diagnostics {
+						var diags tfsdk.Diagnostics
+						if len(v) < 1 {
+							diags = diags.Append(tfsdk.ValidationError(
+								cty.Path(nil).NewErrorf("must have at least one element to specify the report files to read"),
+							))
+						}
+						return diags
+					},
+				},
+
+				"results":  {Type: cty.List(junitResultType), Computed: true},
+				"tests":    {Type: cty.Number, Computed: true},
+				"failures": {Type: cty.Number, Computed: true},
+				"errors":   {Type: cty.Number, Computed: true},
+				"skipped":  {Type: cty.Number, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *junitDRT) (*junitDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			var filenames []string
+			for i, pattern := range obj.Paths {
+				matches, err := filepath.Glob(pattern)
+				if err != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Invalid report path",
+						Detail:   fmt.Sprintf("Invalid path pattern %q: %s.", pattern, err),
+						Path:     cty.Path(nil).GetAttr("paths").Index(cty.NumberIntVal(int64(i))),
+					})
+					continue
+				}
+				if len(matches) == 0 {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test report not found",
+						Detail:   fmt.Sprintf("There are no files matching %q.", pattern),
+						Path:     cty.Path(nil).GetAttr("paths").Index(cty.NumberIntVal(int64(i))),
+					})
+					continue
+				}
+				filenames = append(filenames, matches...)
+			}
+			if diags.HasErrors() {
+				return obj, diags
+			}
+
+			obj.Results = []junitDRTResult{}
+			var tests, failures, errors, skipped int
+			for _, filename := range filenames {
+				suites, err := readJUnitReport(filename)
+				if err != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Invalid test report",
+						Detail:   fmt.Sprintf("Failed to read JUnit XML report %s: %s.", filename, err),
+					})
+					continue
+				}
+
+				for _, suite := range suites {
+					for _, tc := range suite.TestCases {
+						result := junitDRTResult{
+							Suite:     suite.Name,
+							ClassName: tc.ClassName,
+							Name:      tc.Name,
+							Status:    "passed",
+							Time:      tc.Time,
+						}
+						var detail *junitDetail
+						switch {
+						case tc.Failure != nil:
+							result.Status = "failed"
+							detail = tc.Failure
+							failures++
+						case tc.Error != nil:
+							result.Status = "error"
+							detail = tc.Error
+							errors++
+						case tc.Skipped != nil:
+							result.Status = "skipped"
+							detail = tc.Skipped
+							skipped++
+						}
+						tests++
+						if detail != nil {
+							result.Message = detail.Message
+						}
+						obj.Results = append(obj.Results, result)
+
+						if result.Status != "failed" && result.Status != "error" {
+							continue
+						}
+
+						testName := tc.Name
+						if tc.ClassName != "" {
+							testName = tc.ClassName + "." + tc.Name
+						}
+						testDiagMsgs := ""
+						if text := strings.TrimSpace(detail.Message + "\n" + detail.Text); text != "" {
+							testDiagMsgs = "\n\nDiagnostic output from test:\n  " + strings.Replace(text, "\n", "\n  ", -1)
+						}
+						if result.Status == "failed" {
+							diags = diags.Append(tfsdk.Diagnostic{
+								Severity: tfsdk.Error,
+								Summary:  "Test failure",
+								Detail:   fmt.Sprintf("Test failed: %s.%s", testName, testDiagMsgs),
+							})
+						} else {
+							diags = diags.Append(tfsdk.Diagnostic{
+								Severity: tfsdk.Error,
+								Summary:  "Test error",
+								Detail:   fmt.Sprintf("Test encountered an error: %s.%s", testName, testDiagMsgs),
+							})
+						}
+					}
+				}
+			}
+
+			obj.Tests = &tests
+			obj.Failures = &failures
+			obj.Errors = &errors
+			obj.Skipped = &skipped
+			return obj, diags
+		},
+	})
+}
+
+type junitTestSuites struct {
+	Suites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	TestCases []junitTestCase  `xml:"testcase"`
+	Suites    []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestCase struct {
+	ClassName string       `xml:"classname,attr"`
+	Name      string       `xml:"name,attr"`
+	Time      float64      `xml:"time,attr"`
+	Failure   *junitDetail `xml:"failure"`
+	Error     *junitDetail `xml:"error"`
+	Skipped   *junitDetail `xml:"skipped"`
+
+	// Properties is used only in the report the provider writes itself,
+	// to record the outcomes of individual assertions.
+	Properties []junitReportProperty `xml:"properties>property,omitempty"`
+}
+
+type junitDetail struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// readJUnitReport reads a JUnit-style XML test report from the given file,
+// returning a flat list of all of the test suites it contains.
+//
+// There is no formal specification for this format, so this accepts either
+// a single <testsuite> element or a <testsuites> element as the root element,
+// with any level of suite nesting, which covers the output of most of the
+// common tools that produce it.
+func readJUnitReport(filename string) ([]junitTestSuite, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec := xml.NewDecoder(f)
+	var start xml.StartElement
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			start = se
+			break
+		}
+	}
+
+	var suites []junitTestSuite
+	switch start.Name.Local {
+	case "testsuites":
+		var root junitTestSuites
+		err = dec.DecodeElement(&root, &start)
+		suites = root.Suites
+	case "testsuite":
+		var root junitTestSuite
+		err = dec.DecodeElement(&root, &start)
+		suites = []junitTestSuite{root}
+	default:
+		return nil, fmt.Errorf("root element must be either <testsuites> or <testsuite>, not <%s>", start.Name.Local)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return flattenJUnitSuites(suites), nil
+}
+
+func flattenJUnitSuites(suites []junitTestSuite) []junitTestSuite {
+	var ret []junitTestSuite
+	for _, suite := range suites {
+		ret = append(ret, suite)
+		ret = append(ret, flattenJUnitSuites(suite.Suites)...)
+	}
+	return ret
+}
@@ -0,0 +1,89 @@
+package testing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDRTJUnit(t *testing.T) {
+	writeReport := func(t *testing.T, content string) string {
+		f, err := ioutil.TempFile("", "terraform-provider-testing-junit")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(content); err != nil {
+			t.Fatal(err)
+		}
+		return f.Name()
+	}
+
+	t.Run("pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		filename := writeReport(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="example" tests="2">
+    <testcase classname="example.Thing" name="test_a" time="0.5"/>
+    <testcase classname="example.Thing" name="test_b" time="0.25">
+      <skipped message="not today"/>
+    </testcase>
+  </testsuite>
+</testsuites>
+`)
+		defer os.Remove(filename)
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_junit" "test" {
+  paths = [%q]
+}
+
+data "testing_assertions" "test" {
+  equal "counts" {
+	got  = [
+	  data.testing_junit.test.tests,
+	  data.testing_junit.test.failures,
+	  data.testing_junit.test.errors,
+	  data.testing_junit.test.skipped,
+	]
+	want = [2, 0, 0, 1]
+  }
+  equal "skipped" {
+	got  = data.testing_junit.test.results[1].status
+	want = "skipped"
+  }
+}
+`, filename))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		filename := writeReport(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="example" tests="1">
+  <testcase classname="example.Thing" name="test_a">
+    <failure message="expected 1, got 2">Traceback...</failure>
+  </testcase>
+</testsuite>
+`)
+		defer os.Remove(filename)
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_junit" "test" {
+  paths = [%q]
+}
+`, filename))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
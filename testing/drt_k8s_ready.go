@@ -0,0 +1,353 @@
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type k8sReadyDRT struct {
+	Kubeconfig *string `cty:"kubeconfig"`
+	Context    *string `cty:"context"`
+
+	APIVersion string  `cty:"api_version"`
+	Kind       string  `cty:"kind"`
+	Resource   *string `cty:"resource"`
+	Namespace  *string `cty:"namespace"`
+	Name       string  `cty:"name"`
+	Condition  *string `cty:"condition"`
+
+	Timeout  *string `cty:"timeout"`
+	Interval *string `cty:"interval"`
+
+	Conditions []k8sReadyDRTCondition `cty:"conditions"`
+}
+
+type k8sReadyDRTCondition struct {
+	Type    string `cty:"type"`
+	Status  string `cty:"status"`
+	Reason  string `cty:"reason"`
+	Message string `cty:"message"`
+}
+
+var k8sConditionType = cty.Object(map[string]cty.Type{
+	"type":    cty.String,
+	"status":  cty.String,
+	"reason":  cty.String,
+	"message": cty.String,
+})
+
+// k8sObject is the subset of the Kubernetes object structure that we use to
+// decide whether an object is ready. Fields that don't apply to a particular
+// kind of object are left as zero values.
+type k8sObject struct {
+	Metadata struct {
+		Generation int64
+	}
+	Spec struct {
+		Replicas *int64
+	}
+	Status struct {
+		ObservedGeneration int64
+
+		// Deployment and StatefulSet
+		ReadyReplicas     int64
+		UpdatedReplicas   int64
+		AvailableReplicas int64
+
+		// DaemonSet
+		DesiredNumberScheduled int64
+		UpdatedNumberScheduled int64
+		NumberAvailable        int64
+
+		Conditions []k8sReadyDRTCondition
+	}
+}
+
+func k8sReadyDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_k8s_ready", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"kubeconfig": {Type: cty.String, Optional: true},
+				"context":    {Type: cty.String, Optional: true},
+
+				"api_version": {Type: cty.String, Required: true},
+				"kind":        {Type: cty.String, Required: true},
+				"resource":    {Type: cty.String, Optional: true},
+				"namespace":   {Type: cty.String, Optional: true},
+				"name":        {Type: cty.String, Required: true},
+				"condition":   {Type: cty.String, Optional: true},
+
+				"timeout":  {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"interval": {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+
+				"conditions": {Type: cty.List(k8sConditionType), Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *k8sReadyDRT) (*k8sReadyDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			kubeconfigPath := defaultKubeconfigPath()
+			if obj.Kubeconfig != nil {
+				kubeconfigPath = *obj.Kubeconfig
+			}
+			contextName := ""
+			if obj.Context != nil {
+				contextName = *obj.Context
+			}
+			kc, err := newKubeClient(kubeconfigPath, contextName)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid Kubernetes configuration",
+					Detail:   fmt.Sprintf("Cannot prepare a Kubernetes API client: %s.", err),
+				})
+				return obj, diags
+			}
+
+			namespace := kc.Namespace
+			if obj.Namespace != nil {
+				namespace = *obj.Namespace
+			}
+			resource := k8sResourceName(obj.Kind)
+			if obj.Resource != nil {
+				resource = *obj.Resource
+			}
+			condition := ""
+			if obj.Condition != nil {
+				condition = *obj.Condition
+			}
+			reqPath := k8sObjectPath(obj.APIVersion, namespace, resource, obj.Name)
+			subject := fmt.Sprintf("%s %q", obj.Kind, obj.Name)
+			if namespace != "" {
+				subject = fmt.Sprintf("%s %q in namespace %q", obj.Kind, obj.Name, namespace)
+			}
+
+			timeout := client.timeout(obj.Timeout, 5*time.Minute)
+			interval := durationOrDefault(obj.Interval, 2*time.Second)
+			deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			var lastObj *k8sObject
+			var lastErr error
+			var notReady string
+			for {
+				got, err := k8sGetObject(deadlineCtx, kc, reqPath)
+				if err == nil {
+					lastObj = got
+					lastErr = nil
+					var ready, failed bool
+					ready, failed, notReady = k8sReadiness(obj.Kind, condition, got)
+					if ready {
+						break
+					}
+					if failed {
+						obj.Conditions = got.Status.Conditions
+						diags = diags.Append(tfsdk.Diagnostic{
+							Severity: tfsdk.Error,
+							Summary:  "Test failure",
+							Detail:   fmt.Sprintf("%s failed: %s.%s", subject, notReady, k8sConditionsForOutput(got.Status.Conditions)),
+						})
+						return obj, diags
+					}
+				} else if deadlineCtx.Err() == nil || lastErr == nil {
+					lastErr = err
+				}
+
+				select {
+				case <-time.After(interval):
+					continue
+				case <-deadlineCtx.Done():
+				}
+
+				var detail string
+				switch {
+				case lastErr != nil:
+					detail = fmt.Sprintf("Failed to check %s within %s: %s.", subject, timeout, lastErr)
+				default:
+					detail = fmt.Sprintf("%s did not become ready within %s: %s.%s", subject, timeout, notReady, k8sConditionsForOutput(lastObj.Status.Conditions))
+				}
+				if lastObj != nil {
+					obj.Conditions = lastObj.Status.Conditions
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   detail,
+				})
+				return obj, diags
+			}
+
+			obj.Conditions = lastObj.Status.Conditions
+			if obj.Conditions == nil {
+				obj.Conditions = []k8sReadyDRTCondition{}
+			}
+			return obj, diags
+		},
+	}, ownTimeout)
+}
+
+// k8sReadiness decides whether the given object is ready. If it is not ready,
+// it also returns a description of why, and whether the object has failed
+// in a way that it cannot recover from.
+//
+// If condition is non-empty then the object is ready when it has a condition
+// of that type with status "True", regardless of its kind. Otherwise, there
+// are specific rules for some built-in kinds, and a "Ready" condition is
+// required for all others.
+func k8sReadiness(kind, condition string, obj *k8sObject) (ready, failed bool, reason string) {
+	if condition == "" {
+		if obj.Status.ObservedGeneration < obj.Metadata.Generation {
+			return false, false, "the latest changes have not been observed by its controller yet"
+		}
+
+		want := int64(1)
+		if obj.Spec.Replicas != nil {
+			want = *obj.Spec.Replicas
+		}
+
+		switch kind {
+		case "Deployment":
+			switch {
+			case obj.Status.UpdatedReplicas < want:
+				return false, false, fmt.Sprintf("%d of %d replicas have been updated", obj.Status.UpdatedReplicas, want)
+			case obj.Status.AvailableReplicas < want:
+				return false, false, fmt.Sprintf("%d of %d replicas are available", obj.Status.AvailableReplicas, want)
+			}
+			return true, false, ""
+		case "StatefulSet":
+			switch {
+			case obj.Status.UpdatedReplicas < want:
+				return false, false, fmt.Sprintf("%d of %d replicas have been updated", obj.Status.UpdatedReplicas, want)
+			case obj.Status.ReadyReplicas < want:
+				return false, false, fmt.Sprintf("%d of %d replicas are ready", obj.Status.ReadyReplicas, want)
+			}
+			return true, false, ""
+		case "DaemonSet":
+			want = obj.Status.DesiredNumberScheduled
+			switch {
+			case obj.Status.UpdatedNumberScheduled < want:
+				return false, false, fmt.Sprintf("%d of %d pods have been updated", obj.Status.UpdatedNumberScheduled, want)
+			case obj.Status.NumberAvailable < want:
+				return false, false, fmt.Sprintf("%d of %d pods are available", obj.Status.NumberAvailable, want)
+			}
+			return true, false, ""
+		case "Job":
+			for _, c := range obj.Status.Conditions {
+				if c.Type == "Failed" && c.Status == "True" {
+					return false, true, "the job has failed"
+				}
+			}
+			condition = "Complete"
+		default:
+			condition = "Ready"
+		}
+	}
+
+	for _, c := range obj.Status.Conditions {
+		if c.Type == condition {
+			if c.Status == "True" {
+				return true, false, ""
+			}
+			return false, false, fmt.Sprintf("condition %q has status %q", condition, c.Status)
+		}
+	}
+	return false, false, fmt.Sprintf("condition %q is not present", condition)
+}
+
+func k8sGetObject(ctx context.Context, kc *kubeClient, path string) (*k8sObject, error) {
+	req, err := kc.NewRequest("GET", path)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := kc.HTTP.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// The API server returns a Status object describing the error, but
+		// we'll fall back on the HTTP status if we can't decode it.
+		var status struct{ Message string }
+		if json.Unmarshal(body, &status) == nil && status.Message != "" {
+			return nil, fmt.Errorf("the API server returned %q: %s", resp.Status, status.Message)
+		}
+		return nil, fmt.Errorf("the API server returned %q", resp.Status)
+	}
+
+	var obj k8sObject
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, fmt.Errorf("invalid response from the API server: %s", err)
+	}
+	return &obj, nil
+}
+
+// k8sObjectPath returns the API path for the object with the given name. If
+// namespace is empty then the object is assumed to be cluster-scoped.
+func k8sObjectPath(apiVersion, namespace, resource, name string) string {
+	var b strings.Builder
+	if strings.Contains(apiVersion, "/") {
+		b.WriteString("/apis/")
+	} else {
+		// The "core" API group has no name and a different path prefix.
+		b.WriteString("/api/")
+	}
+	b.WriteString(apiVersion)
+	if namespace != "" {
+		b.WriteString("/namespaces/")
+		b.WriteString(url.PathEscape(namespace))
+	}
+	b.WriteString("/")
+	b.WriteString(url.PathEscape(resource))
+	b.WriteString("/")
+	b.WriteString(url.PathEscape(name))
+	return b.String()
+}
+
+// k8sResourceName guesses the plural resource name for the given kind using
+// the same simple rules as the Kubernetes API machinery, which are correct
+// for all of the built-in kinds and most custom ones.
+func k8sResourceName(kind string) string {
+	name := strings.ToLower(kind)
+	switch {
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsAny(name[len(name)-2:len(name)-1], "aeiou"):
+		return name[:len(name)-1] + "ies"
+	default:
+		return name + "s"
+	}
+}
+
+func k8sConditionsForOutput(conditions []k8sReadyDRTCondition) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\nThe object has the following conditions:")
+	for _, c := range conditions {
+		fmt.Fprintf(&b, "\n  %s=%s", c.Type, c.Status)
+		if c.Reason != "" {
+			fmt.Fprintf(&b, " (%s)", c.Reason)
+		}
+		if c.Message != "" {
+			fmt.Fprintf(&b, ": %s", c.Message)
+		}
+	}
+	return b.String()
+}
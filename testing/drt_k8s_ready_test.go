@@ -0,0 +1,213 @@
+package testing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDRTK8sReady(t *testing.T) {
+	var deploymentPolls int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/apis/apps/v1/namespaces/testns/deployments/web", func(w http.ResponseWriter, r *http.Request) {
+		// The deployment becomes available on the second poll.
+		available := 1
+		if atomic.AddInt32(&deploymentPolls, 1) == 1 {
+			available = 0
+		}
+		fmt.Fprintf(w, `{
+			"metadata": {"generation": 2},
+			"spec": {"replicas": 1},
+			"status": {
+				"observedGeneration": 2,
+				"updatedReplicas": 1,
+				"availableReplicas": %d,
+				"conditions": [{"type": "Available", "status": "True"}]
+			}
+		}`, available)
+	})
+	mux.HandleFunc("/apis/batch/v1/namespaces/testns/jobs/migrate", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"status": {
+				"conditions": [{"type": "Failed", "status": "True", "reason": "BackoffLimitExceeded", "message": "Job has reached the specified backoff limit"}]
+			}
+		}`))
+	})
+	mux.HandleFunc("/apis/example.com/v1/namespaces/testns/widgets/thing", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"status": {
+				"conditions": [
+					{"type": "Synced", "status": "True"},
+					{"type": "Ready", "status": "False", "reason": "Pending"}
+				]
+			}
+		}`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"kind": "Status", "message": "not found"}`))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sekrit" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	kubeconfig, err := ioutil.TempFile("", "tf-testing-kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(kubeconfig.Name())
+	fmt.Fprintf(kubeconfig, `
+apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: %s
+users:
+- name: test
+  user:
+    token: sekrit
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+    namespace: testns
+`, server.URL)
+	kubeconfig.Close()
+
+	t.Run("deployment ready", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_k8s_ready" "test" {
+  kubeconfig  = %q
+  api_version = "apps/v1"
+  kind        = "Deployment"
+  name        = "web"
+  interval    = "10ms"
+}
+
+data "testing_assertions" "test" {
+  equal "conditions" {
+	got  = data.testing_k8s_ready.test.conditions[0].type
+	want = "Available"
+  }
+}
+`, kubeconfig.Name()))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("custom condition", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_k8s_ready" "test" {
+  kubeconfig  = %q
+  api_version = "example.com/v1"
+  kind        = "Widget"
+  name        = "thing"
+  condition   = "Synced"
+}
+`, kubeconfig.Name()))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("not ready", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_k8s_ready" "test" {
+  kubeconfig  = %q
+  api_version = "example.com/v1"
+  kind        = "Widget"
+  name        = "thing"
+  timeout     = "200ms"
+  interval    = "10ms"
+}
+`, kubeconfig.Name()))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("job failed", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_k8s_ready" "test" {
+  kubeconfig  = %q
+  api_version = "batch/v1"
+  kind        = "Job"
+  name        = "migrate"
+}
+`, kubeconfig.Name()))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("not found", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_k8s_ready" "test" {
+  kubeconfig  = %q
+  api_version = "apps/v1"
+  kind        = "StatefulSet"
+  name        = "db"
+  timeout     = "200ms"
+  interval    = "10ms"
+}
+`, kubeconfig.Name()))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
+
+func TestK8sResourceName(t *testing.T) {
+	tests := []struct {
+		Kind string
+		Want string
+	}{
+		{"Deployment", "deployments"},
+		{"Ingress", "ingresses"},
+		{"NetworkPolicy", "networkpolicies"},
+		{"Gateway", "gateways"},
+		{"Pod", "pods"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Kind, func(t *testing.T) {
+			got := k8sResourceName(test.Kind)
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
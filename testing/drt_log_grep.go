@@ -0,0 +1,274 @@
+package testing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type logGrepDRT struct {
+	Path             *string           `cty:"path"`
+	Program          []string          `cty:"program"`
+	Environment      map[string]string `cty:"environment"`
+	Since            *string           `cty:"since"`
+	TimestampPattern *string           `cty:"timestamp_pattern"`
+	Require          []string          `cty:"require"`
+	Forbid           []string          `cty:"forbid"`
+	Subject          *string           `cty:"subject"`
+
+	Matches       map[string][]string `cty:"matches"`
+	LinesSearched *int                `cty:"lines_searched"`
+}
+
+// defaultLogTimestampPattern matches the most common timestamp formats in
+// log files, including RFC3339 and the "short-iso" output of journalctl.
+var defaultLogTimestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`)
+
+func logGrepDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_log_grep", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"path": {Type: cty.String, Optional: true},
+				"program": {
+					Type:       cty.List(cty.String),
+					Optional:   true,
+					ValidateFn: validateProgram,
+				},
+				"environment":       {Type: cty.Map(cty.String), Optional: true},
+				"since":             {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"timestamp_pattern": {Type: cty.String, Optional: true, ValidateFn: validateRegexp},
+				"require":           {Type: cty.List(cty.String), Optional: true, ValidateFn: validateRegexps},
+				"forbid":            {Type: cty.List(cty.String), Optional: true, ValidateFn: validateRegexps},
+				"subject":           {Type: cty.String, Optional: true},
+
+				"matches":        {Type: cty.Map(cty.List(cty.String)), Computed: true},
+				"lines_searched": {Type: cty.Number, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *logGrepDRT) (*logGrepDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			if (obj.Path == nil) == (obj.Program == nil) {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid log source",
+					Detail:   "Exactly one of the \"path\" and \"program\" arguments must be set, to select where to read the log from.",
+				})
+				return obj, diags
+			}
+
+			var src io.Reader
+			var attr, source string
+			if obj.Path != nil {
+				attr, source = "path", *obj.Path
+				f, err := os.Open(*obj.Path)
+				if err != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Failed to read log",
+						Detail:   fmt.Sprintf("Error reading log file %s: %s.", source, err),
+						Path:     cty.Path(nil).GetAttr(attr),
+					})
+					return obj, diags
+				}
+				defer f.Close()
+				src = f
+			} else {
+				attr, source = "program", strings.Join(obj.Program, " ")
+				cmd := programCommand(ctx, client, obj.Program, obj.Environment)
+				var stderr bytes.Buffer
+				cmd.Stderr = &stderr
+				release, err := client.acquireProgram(ctx)
+				if err != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Failed to read log",
+						Detail:   fmt.Sprintf("Error running %s: %s.", source, err),
+						Path:     cty.Path(nil).GetAttr(attr),
+					})
+					return obj, diags
+				}
+				out, err := cmd.Output()
+				release()
+				if err != nil {
+					if stderr.Len() != 0 {
+						err = fmt.Errorf("%s\n\n%s", err, bytes.TrimSpace(stderr.Bytes()))
+					}
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Failed to read log",
+						Detail:   fmt.Sprintf("Error running %s: %s.", source, err),
+						Path:     cty.Path(nil).GetAttr(attr),
+					})
+					return obj, diags
+				}
+				src = bytes.NewReader(out)
+			}
+
+			tsPattern := defaultLogTimestampPattern
+			if obj.TimestampPattern != nil {
+				// The pattern was already checked by validateRegexp.
+				tsPattern = regexp.MustCompile(*obj.TimestampPattern)
+			}
+			var cutoff time.Time
+			if obj.Since != nil {
+				cutoff = time.Now().Add(-durationOrDefault(obj.Since, 0))
+			}
+
+			// The patterns were already checked by validateRegexps.
+			require := make([]*regexp.Regexp, len(obj.Require))
+			for i, p := range obj.Require {
+				require[i] = regexp.MustCompile(p)
+			}
+			forbid := make([]*regexp.Regexp, len(obj.Forbid))
+			for i, p := range obj.Forbid {
+				forbid[i] = regexp.MustCompile(p)
+			}
+			obj.Matches = make(map[string][]string)
+			for _, p := range append(append([]string(nil), obj.Require...), obj.Forbid...) {
+				obj.Matches[p] = []string{}
+			}
+
+			searched := 0
+			var lineTime time.Time
+			sc := bufio.NewScanner(src)
+			sc.Buffer(nil, 16*1024*1024)
+			for sc.Scan() {
+				line := sc.Text()
+				if !cutoff.IsZero() {
+					// A line without a timestamp is assumed to continue the
+					// entry that began on the most recent line with one, like
+					// the later lines of a stack trace.
+					if t, ok := logLineTime(tsPattern, line); ok {
+						lineTime = t
+					}
+					if lineTime.Before(cutoff) {
+						continue
+					}
+				}
+				searched++
+				for i, re := range require {
+					if re.MatchString(line) {
+						obj.Matches[obj.Require[i]] = append(obj.Matches[obj.Require[i]], line)
+					}
+				}
+				for i, re := range forbid {
+					if re.MatchString(line) {
+						obj.Matches[obj.Forbid[i]] = append(obj.Matches[obj.Forbid[i]], line)
+					}
+				}
+			}
+			if err := sc.Err(); err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Failed to read log",
+					Detail:   fmt.Sprintf("Error reading log from %s: %s.", source, err),
+					Path:     cty.Path(nil).GetAttr(attr),
+				})
+				return obj, diags
+			}
+			obj.LinesSearched = &searched
+
+			subject := "log"
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+			window := ""
+			if obj.Since != nil {
+				window = fmt.Sprintf(" in the last %s", *obj.Since)
+			}
+			for i, p := range obj.Require {
+				if client.failedFast(diags) {
+					break
+				}
+				if len(obj.Matches[p]) != 0 {
+					continue
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   fmt.Sprintf("Assertion failed: %s contains a line matching %s%s.\n\nNone of the %d lines searched match.", subject, formatValue(cty.StringVal(p), 2), window, searched),
+					Path:     cty.Path(nil).GetAttr("require").Index(cty.NumberIntVal(int64(i))),
+				})
+			}
+			for i, p := range obj.Forbid {
+				if client.failedFast(diags) {
+					break
+				}
+				lines := obj.Matches[p]
+				if len(lines) == 0 {
+					continue
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   fmt.Sprintf("Assertion failed: %s contains no lines matching %s%s.\n\nFound %d matching lines:\n%s", subject, formatValue(cty.StringVal(p), 2), window, len(lines), summarizeLogLines(lines, 5)),
+					Path:     cty.Path(nil).GetAttr("forbid").Index(cty.NumberIntVal(int64(i))),
+				})
+			}
+
+			return obj, diags
+		},
+	})
+}
+
+// logLineTime finds a timestamp in the given log line using the given
+// pattern, returning false if there is no timestamp. If the pattern has a
+// capture group then the timestamp is the text matched by the first group,
+// and otherwise it is the text matched by the whole pattern.
+func logLineTime(pattern *regexp.Regexp, line string) (time.Time, bool) {
+	m := pattern.FindStringSubmatch(line)
+	if m == nil {
+		return time.Time{}, false
+	}
+	s := m[0]
+	if len(m) > 1 {
+		s = m[1]
+	}
+	t, err := parseLogTimestamp(s)
+	return t, err == nil
+}
+
+// parseLogTimestamp parses a timestamp in any of the formats matched by
+// defaultLogTimestampPattern, or any format accepted by parseTimestamp.
+// A timestamp without a time zone is assumed to be in the local time zone.
+func parseLogTimestamp(s string) (time.Time, error) {
+	norm := strings.Replace(strings.Replace(s, " ", "T", 1), ",", ".", 1)
+	for _, layout := range []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999999-0700",
+	} {
+		if t, err := time.Parse(layout, norm); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05.999999999", norm, time.Local); err == nil {
+		return t, nil
+	}
+	return parseTimestamp(s)
+}
+
+// summarizeLogLines returns the first few of the given lines, indented for
+// inclusion in a diagnostic message.
+func summarizeLogLines(lines []string, max int) string {
+	var buf strings.Builder
+	for i, line := range lines {
+		if i == max {
+			fmt.Fprintf(&buf, "    (and %d more)\n", len(lines)-max)
+			break
+		}
+		fmt.Fprintf(&buf, "    %s\n", line)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
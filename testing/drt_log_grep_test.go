@@ -0,0 +1,112 @@
+package testing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDRTLogGrep(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-testing-log-grep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	old := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	recent := time.Now().Add(-time.Minute).Format("2006-01-02T15:04:05-0700")
+	logPath := filepath.Join(dir, "app.log")
+	log := fmt.Sprintf(`%[1]s ERROR database connection refused
+%[1]s INFO starting up
+%[2]s INFO listening on :8080
+%[2]s WARN slow request
+  at handler.go:12
+`, old, recent)
+	if err := ioutil.WriteFile(logPath, []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_log_grep" "test" {
+  path    = %q
+  since   = "15m"
+  require = ["listening on", "handler\\.go"]
+  forbid  = ["ERROR"]
+}
+
+data "testing_log_grep" "program" {
+  program = ["cat", %q]
+  require = ["ERROR"]
+}
+
+data "testing_assertions" "test" {
+  equal "lines_searched" {
+    got  = data.testing_log_grep.test.lines_searched
+    want = 3
+  }
+  equal "program_lines_searched" {
+    got  = data.testing_log_grep.program.lines_searched
+    want = 5
+  }
+}
+`, logPath, logPath))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("fail", func(t *testing.T) {
+		for name, args := range map[string]string{
+			"required":  `require = ["shutting down"]`,
+			"forbidden": `forbid = ["WARN"]`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				wd := testHelper.RequireNewWorkingDir(t)
+				defer wd.Close()
+
+				wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_log_grep" "test" {
+  path  = %q
+  since = "15m"
+  %s
+}
+`, logPath, args))
+
+				wd.RequireInit(t)
+				err := wd.Apply()
+				if err == nil {
+					t.Error("succeeded; want error")
+				}
+			})
+		}
+	})
+}
+
+func TestParseLogTimestamp(t *testing.T) {
+	tests := map[string]time.Time{
+		"2019-04-01T12:00:00Z":          time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC),
+		"2019-04-01 12:00:00.5Z":        time.Date(2019, 4, 1, 12, 0, 0, 500000000, time.UTC),
+		"2019-04-01T12:00:00+0000":      time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC),
+		"2019-04-01T14:00:00+02:00":     time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC),
+		"2019-04-01 12:00:00,250+00:00": time.Date(2019, 4, 1, 12, 0, 0, 250000000, time.UTC),
+		"2019-04-01 12:00:00":           time.Date(2019, 4, 1, 12, 0, 0, 0, time.Local),
+		"1554120000":                    time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC),
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			got, err := parseLogTimestamp(input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !got.Equal(want) {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
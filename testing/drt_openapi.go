@@ -0,0 +1,245 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+type openAPIDRT struct {
+	Spec    string  `cty:"spec"`
+	BaseURL *string `cty:"base_url"`
+	Subject *string `cty:"subject"`
+
+	Requests cty.Value                   `cty:"request"`
+	Results  map[string]openAPIDRTResult `cty:"results"`
+}
+
+type openAPIDRTRequest struct {
+	Method    *string           `cty:"method"`
+	Path      string            `cty:"path"`
+	Headers   map[string]string `cty:"headers"`
+	Body      *string           `cty:"body"`
+	Statement *string           `cty:"statement"`
+}
+
+type openAPIDRTResult struct {
+	OperationID string `cty:"operation_id"`
+	StatusCode  int    `cty:"status_code"`
+}
+
+func openAPIDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_openapi", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"spec":     {Type: cty.String, Required: true},
+				"base_url": {Type: cty.String, Optional: true},
+				"subject":  {Type: cty.String, Optional: true},
+
+				"results": {
+					Type: cty.Map(cty.Object(map[string]cty.Type{
+						"operation_id": cty.String,
+						"status_code":  cty.Number,
+					})),
+					Computed: true,
+				},
+			},
+			NestedBlockTypes: map[string]*tfschema.NestedBlockType{
+				"request": {
+					Nesting: tfschema.NestingMap,
+					Content: tfschema.BlockType{
+						Attributes: map[string]*tfschema.Attribute{
+							"method":    {Type: cty.String, Optional: true},
+							"path":      {Type: cty.String, Required: true},
+							"headers":   {Type: cty.Map(cty.String), Optional: true},
+							"body":      {Type: cty.String, Optional: true},
+							"statement": {Type: cty.String, Optional: true},
+						},
+					},
+				},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *openAPIDRT) (*openAPIDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			spec, err := parseOpenAPISpec(obj.Spec)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid OpenAPI document",
+					Detail:   fmt.Sprintf("Cannot load the given OpenAPI document: %s.", err),
+					Path:     cty.Path(nil).GetAttr("spec"),
+				})
+				return obj, diags
+			}
+
+			baseURL := spec.DefaultServerURL()
+			if obj.BaseURL != nil {
+				baseURL = *obj.BaseURL
+			}
+			base, err := url.Parse(baseURL)
+			if err != nil || !base.IsAbs() {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid base URL",
+					Detail:   "The \"base_url\" argument must be set to an absolute URL, unless the OpenAPI document declares an absolute server URL.",
+					Path:     cty.Path(nil).GetAttr("base_url"),
+				})
+				return obj, diags
+			}
+
+			subject := ""
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+
+			obj.Results = make(map[string]openAPIDRTResult)
+			for it := obj.Requests.ElementIterator(); it.Next(); {
+				if client.failedFast(diags) {
+					break
+				}
+				k, v := it.Element()
+				var req openAPIDRTRequest
+				err := gocty.FromCtyValue(v, &req)
+				if err != nil {
+					// Should never happen; indicates that our struct is wrong.
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Bug in 'testing' provider",
+						Detail:   fmt.Sprintf("The provider encountered a problem while decoding the request %q block: %s.\n\nThis is a bug in the provider; please report it in the provider's issue tracker.", k.AsString(), err),
+					})
+					continue
+				}
+				path := cty.Path(nil).GetAttr("request").Index(k)
+
+				method := "GET"
+				if req.Method != nil {
+					method = strings.ToUpper(*req.Method)
+				}
+				reqPath := req.Path
+				if q := strings.IndexByte(reqPath, '?'); q >= 0 {
+					reqPath = reqPath[:q]
+				}
+				op := spec.FindOperation(method, reqPath)
+				if op == nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   fmt.Sprintf("Undocumented operation: the OpenAPI document does not describe an operation for %s %s.", method, reqPath),
+						Path:     path.GetAttr("path"),
+					})
+					continue
+				}
+
+				status, problems, err := openAPICheckRequest(ctx, spec, op, base, method, req)
+				if err != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Request failed",
+						Detail:   fmt.Sprintf("Error making request %s %s: %s.", method, req.Path, err),
+						Path:     path,
+					})
+					continue
+				}
+				obj.Results[k.AsString()] = openAPIDRTResult{
+					OperationID: op.ID,
+					StatusCode:  status,
+				}
+				if len(problems) == 0 {
+					continue
+				}
+
+				statement := "response conforms to the OpenAPI document"
+				if req.Statement != nil {
+					statement = *req.Statement
+				}
+				if subject != "" {
+					statement = fmt.Sprintf("%s %s", subject, statement)
+				}
+				opName := fmt.Sprintf("%s %s", op.Method, op.PathTemplate)
+				if op.ID != "" {
+					opName = fmt.Sprintf("%s (%s)", opName, op.ID)
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   fmt.Sprintf("Assertion failed: %s.\n\nThe response from %s does not conform to operation %s:\n  - %s", statement, req.Path, opName, strings.Join(problems, "\n  - ")),
+					Path:     path,
+				})
+			}
+
+			return obj, diags
+		},
+	})
+}
+
+// openAPICheckRequest makes the given request and checks whether the response
+// conforms to the given operation, returning the response status code and a
+// description of each problem found.
+func openAPICheckRequest(ctx context.Context, spec *openAPISpec, op *openAPIOperation, base *url.URL, method string, req openAPIDRTRequest) (int, []string, error) {
+	u := strings.TrimRight(base.String(), "/") + "/" + strings.TrimLeft(req.Path, "/")
+	var body *strings.Reader
+	if req.Body != nil {
+		body = strings.NewReader(*req.Body)
+	} else {
+		body = strings.NewReader("")
+	}
+	httpReq, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	headerNames := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		httpReq.Header.Set(name, req.Headers[name])
+	}
+
+	resp, err := http.DefaultClient.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	mediaType := ""
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, err = mime.ParseMediaType(ct)
+		if err != nil {
+			return resp.StatusCode, []string{fmt.Sprintf("response has invalid Content-Type header %q", ct)}, nil
+		}
+	}
+	if len(respBody) == 0 {
+		mediaType = ""
+	}
+
+	schema, err := spec.ResponseSchema(op, resp.StatusCode, mediaType)
+	if err != nil {
+		return resp.StatusCode, []string{err.Error()}, nil
+	}
+	if schema == nil || !openAPIIsJSON(mediaType) {
+		// We can only validate JSON response bodies against a schema.
+		return resp.StatusCode, nil, nil
+	}
+	return resp.StatusCode, spec.ValidateJSON(schema, respBody), nil
+}
+
+func openAPIIsJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,226 @@
+package testing
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testOpenAPISpec = `
+openapi: "3.0.0"
+info:
+  title: Pets
+  version: "1.0"
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200":
+          description: All of the pets.
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Pet"
+  /pets/{id}:
+    get:
+      operationId: getPet
+      responses:
+        "200":
+          description: A single pet.
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pet"
+        "404":
+          description: No such pet.
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+        tag:
+          type: string
+          nullable: true
+        kind:
+          type: string
+          enum: [cat, dog]
+`
+
+func TestDRTOpenAPI(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pets", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id":1,"name":"Rex","tag":null,"kind":"dog"}]`))
+	})
+	mux.HandleFunc("/pets/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(`{"id":1,"name":"Rex"}`))
+	})
+	mux.HandleFunc("/pets/2", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"2","kind":"fish"}`))
+	})
+	mux.HandleFunc("/pets/3", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Run("pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_openapi" "test" {
+  spec     = <<EOT
+%s
+EOT
+  base_url = %q
+
+  request "list" {
+    path = "/pets"
+  }
+  request "get" {
+    path = "/pets/1"
+  }
+  request "missing" {
+    path = "/pets/4"
+  }
+}
+
+data "testing_assertions" "test" {
+  equal "get_operation" {
+    got  = data.testing_openapi.test.results["get"].operation_id
+    want = "getPet"
+  }
+  equal "missing_status" {
+    got  = data.testing_openapi.test.results["missing"].status_code
+    want = 404
+  }
+}
+`, testOpenAPISpec, server.URL))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("schema fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_openapi" "test" {
+  spec     = <<EOT
+%s
+EOT
+  base_url = %q
+
+  request "get" {
+    path = "/pets/2"
+  }
+}
+`, testOpenAPISpec, server.URL))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("status fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_openapi" "test" {
+  spec     = <<EOT
+%s
+EOT
+  base_url = %q
+
+  request "get" {
+    path = "/pets/3"
+  }
+}
+`, testOpenAPISpec, server.URL))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("undocumented operation", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_openapi" "test" {
+  spec     = <<EOT
+%s
+EOT
+  base_url = %q
+
+  request "delete" {
+    method = "DELETE"
+    path   = "/pets"
+  }
+}
+`, testOpenAPISpec, server.URL))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Fatal("succeeded; want error")
+		}
+		for _, want := range []string{"Test failure", "Undocumented operation"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error does not mention %q\n%s", want, err)
+			}
+		}
+	})
+}
+
+func TestOpenAPIValidateJSON(t *testing.T) {
+	spec, err := parseOpenAPISpec(testOpenAPISpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := spec.FindOperation("GET", "/pets/2")
+	if op == nil || op.ID != "getPet" {
+		t.Fatalf("wrong operation %#v", op)
+	}
+	schema, err := spec.ResponseSchema(op, 200, "application/json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]int{
+		`{"id":1,"name":"Rex"}`:                    0,
+		`{"id":1,"name":"Rex","tag":null}`:         0,
+		`{"id":1.5,"name":"Rex"}`:                  1,
+		`{"name":"Rex"}`:                           1,
+		`{"id":"1","kind":"fish"}`:                 3,
+		`[]`:                                       1,
+		`not json`:                                 1,
+		`{"id":1,"name":null,"kind":"cat"}`:        1,
+		`{"id":1,"name":"Rex","extra":[1,2,true]}`: 0,
+	}
+	for body, want := range tests {
+		t.Run(body, func(t *testing.T) {
+			got := spec.ValidateJSON(schema, []byte(body))
+			if len(got) != want {
+				t.Errorf("wrong number of problems %d; want %d\n%#v", len(got), want, got)
+			}
+		})
+	}
+}
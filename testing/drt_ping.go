@@ -0,0 +1,315 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+type pingDRT struct {
+	Host          string   `cty:"host"`
+	Protocol      *string  `cty:"protocol"`
+	Port          *int     `cty:"port"`
+	Probes        *int     `cty:"probes"`
+	Interval      *string  `cty:"interval"`
+	Timeout       *string  `cty:"timeout"`
+	MaxPacketLoss *float64 `cty:"max_packet_loss"`
+	Subject       *string  `cty:"subject"`
+
+	PacketsSent     *int     `cty:"packets_sent"`
+	PacketsReceived *int     `cty:"packets_received"`
+	PacketLoss      *float64 `cty:"packet_loss"`
+	RTTMin          *float64 `cty:"rtt_min"`
+	RTTAvg          *float64 `cty:"rtt_avg"`
+	RTTMax          *float64 `cty:"rtt_max"`
+}
+
+func pingDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_ping", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"host": {Type: cty.String, Required: true},
+				"protocol": {
+					Type:     cty.String,
+					Optional: true,
+					Computed: true,
+					ValidateFn: func(v string) tfsdk.Diagnostics {
+						var diags tfsdk.Diagnostics
+						if v != "icmp" && v != "tcp" {
+							diags = diags.Append(tfsdk.ValidationError(
+								cty.Path(nil).NewErrorf("must be either \"icmp\" or \"tcp\""),
+							))
+						}
+						return diags
+					},
+				},
+				"port":            {Type: cty.Number, Optional: true},
+				"probes":          {Type: cty.Number, Optional: true},
+				"interval":        {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"timeout":         {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"max_packet_loss": {Type: cty.Number, Optional: true},
+				"subject":         {Type: cty.String, Optional: true},
+
+				"packets_sent":     {Type: cty.Number, Computed: true},
+				"packets_received": {Type: cty.Number, Computed: true},
+				"packet_loss":      {Type: cty.Number, Computed: true},
+				"rtt_min":          {Type: cty.Number, Computed: true},
+				"rtt_avg":          {Type: cty.Number, Computed: true},
+				"rtt_max":          {Type: cty.Number, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *pingDRT) (*pingDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			count := 4
+			if obj.Probes != nil {
+				count = *obj.Probes
+			}
+			if count < 1 {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid probe count",
+					Detail:   "The \"probes\" argument must be at least 1.",
+					Path:     cty.Path(nil).GetAttr("probes"),
+				})
+				return obj, diags
+			}
+			interval := durationOrDefault(obj.Interval, time.Second)
+			timeout := client.timeout(obj.Timeout, time.Second)
+
+			if obj.Protocol != nil && *obj.Protocol == "tcp" && obj.Port == nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Missing port",
+					Detail:   "The \"port\" argument is required when \"protocol\" is \"tcp\".",
+					Path:     cty.Path(nil).GetAttr("port"),
+				})
+				return obj, diags
+			}
+
+			addr, err := net.ResolveIPAddr("ip", obj.Host)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Cannot resolve host",
+					Detail:   fmt.Sprintf("Error resolving %q: %s.", obj.Host, err),
+					Path:     cty.Path(nil).GetAttr("host"),
+				})
+				return obj, diags
+			}
+
+			// Unless told otherwise, we prefer ICMP but will fall back to TCP
+			// if we aren't permitted to send ICMP messages and a port is set.
+			var probe func(seq int) (time.Duration, bool)
+			protocol := "tcp"
+			if obj.Protocol == nil || *obj.Protocol == "icmp" {
+				pinger, err := newICMPPinger(addr.IP)
+				switch {
+				case err == nil:
+					defer pinger.Close()
+					protocol = "icmp"
+					probe = func(seq int) (time.Duration, bool) {
+						return pinger.Ping(seq, timeout)
+					}
+				case obj.Protocol == nil && obj.Port != nil:
+					// Fall back to TCP below.
+				default:
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Cannot send ICMP messages",
+						Detail:   fmt.Sprintf("Failed to open an ICMP socket: %s.\n\nTo check reachability without ICMP, set \"port\" to the number of a TCP port that the host is listening on.", err),
+						Path:     cty.Path(nil).GetAttr("protocol"),
+					})
+					return obj, diags
+				}
+			}
+			if probe == nil {
+				target := net.JoinHostPort(addr.IP.String(), strconv.Itoa(*obj.Port))
+				probe = func(seq int) (time.Duration, bool) {
+					return tcpPing(ctx, target, timeout)
+				}
+			}
+			if obj.Protocol == nil {
+				obj.Protocol = &protocol
+			}
+
+			var rtts []time.Duration
+			for seq := 0; seq < count; seq++ {
+				if seq > 0 {
+					select {
+					case <-time.After(interval):
+					case <-ctx.Done():
+						diags = diags.Append(tfsdk.Diagnostic{
+							Severity: tfsdk.Error,
+							Summary:  "Ping cancelled",
+							Detail:   fmt.Sprintf("Cancelled after sending %d of %d probes.", seq, count),
+						})
+						return obj, diags
+					}
+				}
+				if rtt, ok := probe(seq); ok {
+					rtts = append(rtts, rtt)
+				}
+			}
+
+			received := len(rtts)
+			loss := float64(count-received) * 100 / float64(count)
+			obj.PacketsSent, obj.PacketsReceived, obj.PacketLoss = &count, &received, &loss
+			if len(rtts) > 0 {
+				min, max, sum := math.Inf(1), 0.0, 0.0
+				for _, rtt := range rtts {
+					ms := float64(rtt) / float64(time.Millisecond)
+					min = math.Min(min, ms)
+					max = math.Max(max, ms)
+					sum += ms
+				}
+				avg := sum / float64(len(rtts))
+				obj.RTTMin, obj.RTTAvg, obj.RTTMax = &min, &avg, &max
+			}
+
+			maxLoss := 0.0
+			if obj.MaxPacketLoss != nil {
+				maxLoss = *obj.MaxPacketLoss
+			}
+			if loss > maxLoss {
+				statement := fmt.Sprintf("%s is reachable", obj.Host)
+				if obj.Subject != nil {
+					statement = fmt.Sprintf("%s is reachable", *obj.Subject)
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail: fmt.Sprintf(
+						"Assertion failed: %s.\n  Want packet loss of at most: %s%%\n  Got packet loss:             %s%%\n\n%d of %d %s probes received a response.",
+						statement,
+						strconv.FormatFloat(maxLoss, 'f', -1, 64),
+						strconv.FormatFloat(loss, 'f', -1, 64),
+						received, count, protocol,
+					),
+					Path: cty.Path(nil).GetAttr("max_packet_loss"),
+				})
+			}
+
+			return obj, diags
+		},
+	}, ownTimeout)
+}
+
+// tcpPing measures the time taken to establish a TCP connection to the given
+// address, which stands in for an ICMP echo round trip.
+func tcpPing(ctx context.Context, addr string, timeout time.Duration) (time.Duration, bool) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return 0, false
+	}
+	rtt := time.Since(start)
+	conn.Close()
+	return rtt, true
+}
+
+// icmpPinger sends ICMP echo requests to a single address.
+type icmpPinger struct {
+	conn      *icmp.PacketConn
+	dst       net.Addr
+	id        int
+	proto     int
+	echoType  icmp.Type
+	replyType icmp.Type
+}
+
+// newICMPPinger opens an ICMP socket suitable for pinging the given address.
+//
+// We first try an unprivileged "ping socket", which Linux and macOS offer to
+// ordinary users, and then fall back on a raw socket, which usually
+// requires elevated privileges.
+func newICMPPinger(ip net.IP) (*icmpPinger, error) {
+	p := &icmpPinger{
+		id: os.Getpid() & 0xffff,
+	}
+	var udpNet, rawNet, listenAddr string
+	if ip.To4() != nil {
+		udpNet, rawNet, listenAddr = "udp4", "ip4:icmp", "0.0.0.0"
+		p.proto = 1 // ICMP for IPv4
+		p.echoType, p.replyType = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	} else {
+		udpNet, rawNet, listenAddr = "udp6", "ip6:ipv6-icmp", "::"
+		p.proto = 58 // ICMP for IPv6
+		p.echoType, p.replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+
+	conn, err := icmp.ListenPacket(udpNet, listenAddr)
+	if err == nil {
+		p.conn = conn
+		p.dst = &net.UDPAddr{IP: ip}
+		return p, nil
+	}
+	conn, err = icmp.ListenPacket(rawNet, listenAddr)
+	if err != nil {
+		return nil, err
+	}
+	p.conn = conn
+	p.dst = &net.IPAddr{IP: ip}
+	return p, nil
+}
+
+// Ping sends a single echo request with the given sequence number and waits
+// up to the given timeout for the matching reply.
+func (p *icmpPinger) Ping(seq int, timeout time.Duration) (time.Duration, bool) {
+	msg := icmp.Message{
+		Type: p.echoType,
+		Body: &icmp.Echo{
+			ID:   p.id,
+			Seq:  seq,
+			Data: []byte("terraform-provider-testing"),
+		},
+	}
+	buf, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, false
+	}
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+	if _, err := p.conn.WriteTo(buf, p.dst); err != nil {
+		return 0, false
+	}
+	if err := p.conn.SetReadDeadline(deadline); err != nil {
+		return 0, false
+	}
+	reply := make([]byte, 1500)
+	for {
+		n, _, err := p.conn.ReadFrom(reply)
+		if err != nil {
+			return 0, false
+		}
+		rm, err := icmp.ParseMessage(p.proto, reply[:n])
+		if err != nil || rm.Type != p.replyType {
+			continue
+		}
+		// Ping sockets rewrite the identifier, so we match only on the
+		// sequence number.
+		if echo, ok := rm.Body.(*icmp.Echo); ok && echo.Seq == seq {
+			return time.Since(start), true
+		}
+	}
+}
+
+func (p *icmpPinger) Close() error {
+	return p.conn.Close()
+}
@@ -0,0 +1,111 @@
+package testing
+
+import (
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestDRTPing(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	// We need a port that nothing is listening on, so we briefly listen on
+	// one to reserve a number and then close it again.
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	t.Run("tcp pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_ping" "test" {
+  host     = "127.0.0.1"
+  protocol = "tcp"
+  port     = %d
+  probes   = 3
+  interval = "10ms"
+}
+
+data "testing_assertions" "test" {
+  equal "received" {
+    got  = data.testing_ping.test.packets_received
+    want = 3
+  }
+  check "rtt" {
+    expect = data.testing_ping.test.rtt_min <= data.testing_ping.test.rtt_max
+  }
+}
+`, port))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("tcp fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_ping" "test" {
+  host     = "127.0.0.1"
+  protocol = "tcp"
+  port     = %d
+  probes   = 2
+  interval = "10ms"
+  timeout  = "200ms"
+}
+`, closedPort))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("icmp", func(t *testing.T) {
+		pinger, err := newICMPPinger(net.ParseIP("127.0.0.1"))
+		if err != nil {
+			t.Skipf("cannot send ICMP messages in this environment: %s", err)
+		}
+		pinger.Close()
+
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_ping" "test" {
+  host     = "127.0.0.1"
+  probes   = 2
+  interval = "10ms"
+}
+
+data "testing_assertions" "test" {
+  equal "protocol" {
+    got  = data.testing_ping.test.protocol
+    want = "icmp"
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+}
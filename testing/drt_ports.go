@@ -0,0 +1,163 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type portsDRT struct {
+	Host        string            `cty:"host"`
+	Ports       map[string]string `cty:"ports"`
+	Timeout     *string           `cty:"timeout"`
+	Concurrency *int              `cty:"concurrency"`
+	Subject     *string           `cty:"subject"`
+
+	Results map[string]string `cty:"results"`
+}
+
+func portsDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_ports", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"host":        {Type: cty.String, Required: true},
+				"ports":       {Type: cty.Map(cty.String), Required: true, ValidateFn: validatePortStates},
+				"timeout":     {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"concurrency": {Type: cty.Number, Optional: true},
+				"subject":     {Type: cty.String, Optional: true},
+
+				"results": {Type: cty.Map(cty.String), Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *portsDRT) (*portsDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			timeout := client.timeout(obj.Timeout, 2*time.Second)
+			concurrency := 16
+			if obj.Concurrency != nil {
+				concurrency = *obj.Concurrency
+			}
+			if concurrency < 1 {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid concurrency",
+					Detail:   "The \"concurrency\" argument must be at least 1.",
+					Path:     cty.Path(nil).GetAttr("concurrency"),
+				})
+				return obj, diags
+			}
+			subject := obj.Host
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+
+			ports := make([]string, 0, len(obj.Ports))
+			for port := range obj.Ports {
+				ports = append(ports, port)
+			}
+			sort.Slice(ports, func(i, j int) bool {
+				// The keys were already checked by validatePortStates.
+				a, _ := strconv.Atoi(ports[i])
+				b, _ := strconv.Atoi(ports[j])
+				return a < b
+			})
+
+			results := make([]string, len(ports))
+			sem := make(chan struct{}, concurrency)
+			var wg sync.WaitGroup
+			for i, port := range ports {
+				wg.Add(1)
+				go func(i int, port string) {
+					defer wg.Done()
+					sem <- struct{}{}
+					defer func() { <-sem }()
+					results[i] = probePort(ctx, net.JoinHostPort(obj.Host, port), timeout)
+				}(i, port)
+			}
+			wg.Wait()
+
+			obj.Results = make(map[string]string, len(ports))
+			for i, port := range ports {
+				got := results[i]
+				obj.Results[port] = got
+				want := obj.Ports[port]
+
+				// A port that is filtered by a firewall is not reachable, and
+				// so it counts as closed for the purpose of the assertion.
+				if want == got || (want == "closed" && got == "filtered") {
+					continue
+				}
+				// Diagnostics refer only to the "ports" argument as a whole,
+				// because Terraform 0.12 crashes when trying to find the
+				// source location of an element whose key is written as a
+				// bare number, as port numbers usually are.
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   namedAssertionFailureMsg(fmt.Sprintf("%s has port %s %s", subject, port, want), "state", cty.StringVal(want), cty.StringVal(got)),
+					Path:     cty.Path(nil).GetAttr("ports"),
+				})
+			}
+
+			return obj, diags
+		},
+	}, ownTimeout)
+}
+
+// probePort attempts a TCP connection to the given address and returns
+// "open" if it succeeds, "closed" if the connection is actively refused, or
+// "filtered" if there is no response before the timeout or the connection
+// fails in some other way.
+func probePort(ctx context.Context, addr string, timeout time.Duration) string {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err == nil {
+		conn.Close()
+		return "open"
+	}
+	if isConnRefused(err) {
+		return "closed"
+	}
+	return "filtered"
+}
+
+// validatePortStates is a ValidateFn for a map from TCP port number to the
+// expected state of that port, which is either "open" or "closed".
+//
+// For the same reason as in the ReadFn, errors refer to the whole map rather
+// than to the invalid element.
+func validatePortStates(v map[string]string) tfsdk.Diagnostics {
+	var diags tfsdk.Diagnostics
+	for port, state := range v {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			diags = diags.Append(tfsdk.ValidationError(
+				cty.Path(nil).NewErrorf("key %q must be a TCP port number between 1 and 65535", port),
+			))
+		}
+		if state != "open" && state != "closed" {
+			diags = diags.Append(tfsdk.ValidationError(
+				cty.Path(nil).NewErrorf("state for port %s must be either \"open\" or \"closed\"", port),
+			))
+		}
+	}
+	return diags
+}
+
+// isConnRefused returns true if the given error from dialing a TCP
+// connection means that the remote host actively refused it.
+func isConnRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
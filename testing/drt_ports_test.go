@@ -0,0 +1,93 @@
+package testing
+
+import (
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestDRTPorts(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	openPort := ln.Addr().(*net.TCPAddr).Port
+
+	// We find a closed port by briefly listening on one and then closing it
+	// again.
+	ln2, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := ln2.Addr().(*net.TCPAddr).Port
+	ln2.Close()
+
+	t.Run("pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_ports" "test" {
+  host = "127.0.0.1"
+  ports = {
+    %[1]d = "open"
+    %[2]d = "closed"
+  }
+}
+
+data "testing_assertions" "test" {
+  equal "results" {
+    got = data.testing_ports.test.results
+    want = tomap({
+      %[1]d = "open"
+      %[2]d = "closed"
+    })
+  }
+}
+`, openPort, closedPort))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_ports" "test" {
+  host = "127.0.0.1"
+  ports = {
+    %[1]d = "closed"
+    %[2]d = "open"
+  }
+}
+`, openPort, closedPort))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_ports" "test" {
+  host = "127.0.0.1"
+  ports = {
+    http = "open"
+    22   = "listening"
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type processDRT struct {
+	Name            *string `cty:"name"`
+	PIDFile         *string `cty:"pidfile"`
+	Running         *bool   `cty:"running"`
+	ExpectListening []int   `cty:"expect_listening"`
+	Subject         *string `cty:"subject"`
+
+	PIDs           []int `cty:"pids"`
+	ListeningPorts []int `cty:"listening_ports"`
+}
+
+func processDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_process", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"name":             {Type: cty.String, Optional: true},
+				"pidfile":          {Type: cty.String, Optional: true},
+				"running":          {Type: cty.Bool, Optional: true},
+				"expect_listening": {Type: cty.List(cty.Number), Optional: true},
+				"subject":          {Type: cty.String, Optional: true},
+
+				"pids":            {Type: cty.List(cty.Number), Computed: true},
+				"listening_ports": {Type: cty.List(cty.Number), Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *processDRT) (*processDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			if (obj.Name == nil) == (obj.PIDFile == nil) {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid process selection",
+					Detail:   "Exactly one of the \"name\" and \"pidfile\" arguments must be set, to select which process to check.",
+				})
+				return obj, diags
+			}
+			wantRunning := obj.Running == nil || *obj.Running
+			if !wantRunning && len(obj.ExpectListening) != 0 {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid process assertions",
+					Detail:   "The \"expect_listening\" argument cannot be used when \"running\" is false.",
+					Path:     cty.Path(nil).GetAttr("expect_listening"),
+				})
+				return obj, diags
+			}
+
+			var pids []int
+			var err error
+			var attr, subject string
+			if obj.Name != nil {
+				attr, subject = "name", fmt.Sprintf("process %q", *obj.Name)
+				pids, err = findProcesses(*obj.Name)
+			} else {
+				attr, subject = "pidfile", fmt.Sprintf("process in %s", *obj.PIDFile)
+				pids, err = processFromPIDFile(*obj.PIDFile)
+			}
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Failed to find process",
+					Detail:   fmt.Sprintf("Error looking for %s: %s.", subject, err),
+					Path:     cty.Path(nil).GetAttr(attr),
+				})
+				return obj, diags
+			}
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+			if pids == nil {
+				pids = []int{}
+			}
+			obj.PIDs = pids
+			obj.ListeningPorts = []int{}
+
+			running := len(pids) > 0
+			if running != wantRunning {
+				detail := fmt.Sprintf("Assertion failed: %s is running.\n\nNo matching process was found.", subject)
+				if !wantRunning {
+					detail = fmt.Sprintf("Assertion failed: %s is not running.\n\nFound matching process ids: %s.", subject, joinInts(pids))
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   detail,
+					Path:     cty.Path(nil).GetAttr(attr),
+				})
+				return obj, diags
+			}
+			if !running {
+				return obj, diags
+			}
+
+			ports, err := processListeningPorts(pids)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Failed to inspect process",
+					Detail:   fmt.Sprintf("Error finding the listening sockets of %s: %s.", subject, err),
+					Path:     cty.Path(nil).GetAttr(attr),
+				})
+				return obj, diags
+			}
+			if ports != nil {
+				obj.ListeningPorts = ports
+			}
+
+			var missing []int
+			for _, want := range obj.ExpectListening {
+				found := false
+				for _, got := range ports {
+					if got == want {
+						found = true
+						break
+					}
+				}
+				if !found {
+					missing = append(missing, want)
+				}
+			}
+			if len(missing) != 0 {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   fmt.Sprintf("Assertion failed: %s is listening on the expected TCP ports.\n  Missing:   %s\n  Listening: %s", subject, joinInts(missing), joinInts(ports)),
+					Path:     cty.Path(nil).GetAttr("expect_listening"),
+				})
+			}
+
+			return obj, diags
+		},
+	})
+}
+
+// processFromPIDFile reads a process id from the given file and returns it
+// if that process is running, or returns no process ids if either the
+// process or the file itself does not exist.
+func processFromPIDFile(path string) ([]int, error) {
+	src, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(src)))
+	if err != nil || pid < 1 {
+		return nil, fmt.Errorf("%s does not contain a valid process id", path)
+	}
+	running, err := processRunning(pid)
+	if err != nil || !running {
+		return nil, err
+	}
+	return []int{pid}, nil
+}
+
+// joinInts returns a comma-separated list of the given integers, or "(none)"
+// if there are none.
+func joinInts(ns []int) string {
+	if len(ns) == 0 {
+		return "(none)"
+	}
+	strs := make([]string, len(ns))
+	for i, n := range ns {
+		strs[i] = strconv.Itoa(n)
+	}
+	return strings.Join(strs, ", ")
+}
@@ -0,0 +1,115 @@
+package testing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestDRTProcess(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("testing_process is supported only on Linux")
+	}
+
+	// The test program itself is the process under test, listening on a
+	// port chosen by the kernel.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	dir, err := ioutil.TempDir("", "tf-testing-process")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pidfile := filepath.Join(dir, "test.pid")
+	if err := ioutil.WriteFile(pidfile, []byte(fmt.Sprintf("%d\n", os.Getpid())), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("running", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_process" "test" {
+  pidfile          = %q
+  expect_listening = [%d]
+}
+
+data "testing_process" "by_name" {
+  name = %q
+}
+
+data "testing_assertions" "test" {
+  equal "pids" {
+    got  = data.testing_process.test.pids
+    want = tolist([%d])
+  }
+  check "by_name" {
+    expect = contains(data.testing_process.by_name.pids, %d)
+  }
+}
+`, pidfile, port, filepath.Base(os.Args[0]), os.Getpid(), os.Getpid()))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("not running", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_process" "pidfile" {
+  pidfile = %q
+  running = false
+}
+
+data "testing_process" "name" {
+  name    = "tf-testing-nonexistent"
+  running = false
+}
+`, filepath.Join(dir, "nonexistent.pid")))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("fail", func(t *testing.T) {
+		ln2, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		closedPort := ln2.Addr().(*net.TCPAddr).Port
+		ln2.Close()
+
+		for name, config := range map[string]string{
+			"not running": `name = "tf-testing-nonexistent"`,
+			"running":     fmt.Sprintf("pidfile = %q\n  running = false", pidfile),
+			"listening":   fmt.Sprintf("pidfile = %q\n  expect_listening = [%d]", pidfile, closedPort),
+		} {
+			t.Run(name, func(t *testing.T) {
+				wd := testHelper.RequireNewWorkingDir(t)
+				defer wd.Close()
+
+				wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_process" "test" {
+  %s
+}
+`, config))
+
+				wd.RequireInit(t)
+				err := wd.Apply()
+				if err == nil {
+					t.Error("succeeded; want error")
+				}
+			})
+		}
+	})
+}
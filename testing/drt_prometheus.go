@@ -0,0 +1,456 @@
+package testing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+type prometheusDRT struct {
+	URL           *string `cty:"url"`
+	PrometheusURL *string `cty:"prometheus_url"`
+	Subject       *string `cty:"subject"`
+
+	Metrics cty.Value          `cty:"metric"`
+	Values  map[string]float64 `cty:"values"`
+}
+
+type prometheusDRTMetric struct {
+	Name      *string           `cty:"name"`
+	Labels    map[string]string `cty:"labels"`
+	Query     *string           `cty:"query"`
+	Statement *string           `cty:"statement"`
+
+	Equal *float64 `cty:"equal"`
+	Min   *float64 `cty:"min"`
+	Max   *float64 `cty:"max"`
+}
+
+// prometheusSample is a single sample from the Prometheus text exposition
+// format.
+type prometheusSample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+func prometheusDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_prometheus", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"url":            {Type: cty.String, Optional: true},
+				"prometheus_url": {Type: cty.String, Optional: true},
+				"subject":        {Type: cty.String, Optional: true},
+
+				"values": {Type: cty.Map(cty.Number), Computed: true},
+			},
+			NestedBlockTypes: map[string]*tfschema.NestedBlockType{
+				"metric": {
+					Nesting: tfschema.NestingMap,
+					Content: tfschema.BlockType{
+						Attributes: map[string]*tfschema.Attribute{
+							"name":      {Type: cty.String, Optional: true},
+							"labels":    {Type: cty.Map(cty.String), Optional: true},
+							"query":     {Type: cty.String, Optional: true},
+							"statement": {Type: cty.String, Optional: true},
+
+							"equal": {Type: cty.Number, Optional: true},
+							"min":   {Type: cty.Number, Optional: true},
+							"max":   {Type: cty.Number, Optional: true},
+						},
+					},
+				},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *prometheusDRT) (*prometheusDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			if (obj.URL == nil) == (obj.PrometheusURL == nil) {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid Prometheus configuration",
+					Detail:   "Exactly one of the \"url\" and \"prometheus_url\" arguments must be set, to specify either a metrics endpoint to scrape or a Prometheus server to query.",
+				})
+				return obj, diags
+			}
+
+			subject := ""
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+
+			var samples []prometheusSample
+			if obj.URL != nil {
+				var err error
+				samples, err = scrapePrometheusMetrics(ctx, *obj.URL)
+				if err != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Failed to scrape metrics",
+						Detail:   fmt.Sprintf("Failed to read metrics from %s: %s.", *obj.URL, err),
+						Path:     cty.Path(nil).GetAttr("url"),
+					})
+					return obj, diags
+				}
+			}
+
+			obj.Values = make(map[string]float64)
+			for it := obj.Metrics.ElementIterator(); it.Next(); {
+				if client.failedFast(diags) {
+					break
+				}
+				k, v := it.Element()
+				var m prometheusDRTMetric
+				err := gocty.FromCtyValue(v, &m)
+				if err != nil {
+					// Should never happen; indicates that our struct is wrong.
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Bug in 'testing' provider",
+						Detail:   fmt.Sprintf("The provider encountered a problem while decoding the metric %q block: %s.\n\nThis is a bug in the provider; please report it in the provider's issue tracker.", k.AsString(), err),
+					})
+					continue
+				}
+				path := cty.Path(nil).GetAttr("metric").Index(k)
+
+				var value float64
+				var count int
+				switch {
+				case m.Query != nil && obj.PrometheusURL == nil:
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Invalid metric block",
+						Detail:   "The \"query\" argument can be used only when querying a Prometheus server using \"prometheus_url\".",
+						Path:     path.GetAttr("query"),
+					})
+					continue
+				case (m.Name == nil) == (m.Query == nil):
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Invalid metric block",
+						Detail:   "Exactly one of the \"name\" and \"query\" arguments must be set.",
+						Path:     path,
+					})
+					continue
+				case obj.PrometheusURL != nil:
+					query := ""
+					if m.Query != nil {
+						query = *m.Query
+					} else {
+						query = prometheusSelector(*m.Name, m.Labels)
+					}
+					value, count, err = queryPrometheus(ctx, *obj.PrometheusURL, query)
+					if err != nil {
+						diags = diags.Append(tfsdk.Diagnostic{
+							Severity: tfsdk.Error,
+							Summary:  "Prometheus query failed",
+							Detail:   fmt.Sprintf("Failed to evaluate the query %s: %s.", query, err),
+							Path:     path,
+						})
+						continue
+					}
+				default:
+					value, count = sumPrometheusSamples(samples, *m.Name, m.Labels)
+				}
+
+				statement := ""
+				if m.Statement != nil {
+					if subject != "" {
+						statement = fmt.Sprintf("%s %s", subject, *m.Statement)
+					} else {
+						statement = *m.Statement
+					}
+				}
+
+				if count == 0 {
+					detail := "Assertion failed: no samples match the metric selector."
+					if statement != "" {
+						detail = fmt.Sprintf("Assertion failed: %s.\n\nNo samples match the metric selector.", statement)
+					}
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   detail,
+						Path:     path,
+					})
+					continue
+				}
+				if math.IsNaN(value) || math.IsInf(value, 0) {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Unsupported metric value",
+						Detail:   fmt.Sprintf("The metric has the value %v, which cannot be represented as a Terraform number.", value),
+						Path:     path,
+					})
+					continue
+				}
+				obj.Values[k.AsString()] = value
+
+				got := cty.NumberFloatVal(value)
+				if m.Equal != nil && value != *m.Equal {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   namedAssertionFailureMsg(statement, "value", cty.NumberFloatVal(*m.Equal), got),
+						Path:     path.GetAttr("equal"),
+					})
+				}
+				if m.Min != nil && value < *m.Min {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   namedAssertionFailureMsg(statement, "minimum", cty.NumberFloatVal(*m.Min), got),
+						Path:     path.GetAttr("min"),
+					})
+				}
+				if m.Max != nil && value > *m.Max {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   namedAssertionFailureMsg(statement, "maximum", cty.NumberFloatVal(*m.Max), got),
+						Path:     path.GetAttr("max"),
+					})
+				}
+			}
+
+			return obj, diags
+		},
+	})
+}
+
+func scrapePrometheusMetrics(ctx context.Context, url string) ([]prometheusSample, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/plain;version=0.0.4")
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned %q", resp.Status)
+	}
+	return readPrometheusText(resp.Body)
+}
+
+// readPrometheusText parses the Prometheus text exposition format, ignoring
+// comments and timestamps.
+func readPrometheusText(r io.Reader) ([]prometheusSample, error) {
+	var samples []prometheusSample
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1024*1024)
+	lineNum := 0
+	for sc.Scan() {
+		lineNum++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		sample, err := parsePrometheusSample(line)
+		if err != nil {
+			return nil, fmt.Errorf("on line %d: %s", lineNum, err)
+		}
+		samples = append(samples, sample)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+func parsePrometheusSample(line string) (prometheusSample, error) {
+	sample := prometheusSample{Labels: map[string]string{}}
+
+	nameEnd := strings.IndexAny(line, "{ \t")
+	if nameEnd <= 0 {
+		return sample, fmt.Errorf("missing metric value")
+	}
+	sample.Name = line[:nameEnd]
+	rest := line[nameEnd:]
+
+	if rest[0] == '{' {
+		rest = rest[1:]
+		for {
+			rest = strings.TrimLeft(rest, " \t,")
+			if rest == "" {
+				return sample, fmt.Errorf("unterminated label set")
+			}
+			if rest[0] == '}' {
+				rest = rest[1:]
+				break
+			}
+			eq := strings.IndexByte(rest, '=')
+			if eq <= 0 || len(rest) < eq+2 || rest[eq+1] != '"' {
+				return sample, fmt.Errorf("invalid label syntax")
+			}
+			labelName := strings.TrimSpace(rest[:eq])
+			rest = rest[eq+2:]
+
+			var val strings.Builder
+			closed := false
+			for i := 0; i < len(rest); i++ {
+				c := rest[i]
+				if c == '"' {
+					rest = rest[i+1:]
+					closed = true
+					break
+				}
+				if c == '\\' && i+1 < len(rest) {
+					i++
+					switch rest[i] {
+					case 'n':
+						val.WriteByte('\n')
+					default:
+						val.WriteByte(rest[i])
+					}
+					continue
+				}
+				val.WriteByte(c)
+			}
+			if !closed {
+				return sample, fmt.Errorf("unterminated label value for %q", labelName)
+			}
+			sample.Labels[labelName] = val.String()
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return sample, fmt.Errorf("missing metric value")
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return sample, fmt.Errorf("invalid metric value %q", fields[0])
+	}
+	sample.Value = v
+	return sample, nil
+}
+
+// sumPrometheusSamples returns the sum of the values of all samples with the
+// given name whose labels include all of the given labels, along with the
+// number of samples that matched.
+func sumPrometheusSamples(samples []prometheusSample, name string, labels map[string]string) (float64, int) {
+	var sum float64
+	count := 0
+Samples:
+	for _, s := range samples {
+		if s.Name != name {
+			continue
+		}
+		for k, v := range labels {
+			if s.Labels[k] != v {
+				continue Samples
+			}
+		}
+		sum += s.Value
+		count++
+	}
+	return sum, count
+}
+
+// prometheusSelector returns a PromQL expression that sums the values of all
+// series with the given name and label values, for consistency with how
+// sumPrometheusSamples treats scraped metrics.
+func prometheusSelector(name string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	matchers := make([]string, len(keys))
+	for i, k := range keys {
+		matchers[i] = fmt.Sprintf("%s=%s", k, strconv.Quote(labels[k]))
+	}
+	if len(matchers) == 0 {
+		return fmt.Sprintf("sum(%s)", name)
+	}
+	return fmt.Sprintf("sum(%s{%s})", name, strings.Join(matchers, ","))
+}
+
+// queryPrometheus evaluates the given PromQL query using the Prometheus HTTP
+// API at the given base URL, and returns the sum of the values in the result
+// along with the number of values.
+func queryPrometheus(ctx context.Context, baseURL, query string) (float64, int, error) {
+	u := strings.TrimRight(baseURL, "/") + "/api/v1/query?query=" + url.QueryEscape(query)
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var result struct {
+		Status string
+		Error  string
+		Data   struct {
+			ResultType string
+			Result     json.RawMessage
+		}
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, 0, fmt.Errorf("server returned %q with an invalid response body", resp.Status)
+	}
+	if result.Status != "success" {
+		return 0, 0, fmt.Errorf("server returned %q: %s", resp.Status, result.Error)
+	}
+
+	// Sample values are given as [timestamp, "value"] pairs.
+	type samplePair [2]interface{}
+	var pairs []samplePair
+	switch result.Data.ResultType {
+	case "vector":
+		var vector []struct{ Value samplePair }
+		if err := json.Unmarshal(result.Data.Result, &vector); err != nil {
+			return 0, 0, fmt.Errorf("invalid vector result: %s", err)
+		}
+		for _, s := range vector {
+			pairs = append(pairs, s.Value)
+		}
+	case "scalar":
+		var scalar samplePair
+		if err := json.Unmarshal(result.Data.Result, &scalar); err != nil {
+			return 0, 0, fmt.Errorf("invalid scalar result: %s", err)
+		}
+		pairs = append(pairs, scalar)
+	default:
+		return 0, 0, fmt.Errorf("query produced a %s result, but only vector and scalar results are supported", result.Data.ResultType)
+	}
+
+	var sum float64
+	for _, pair := range pairs {
+		raw, ok := pair[1].(string)
+		if !ok {
+			return 0, 0, fmt.Errorf("invalid sample value %v", pair[1])
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid sample value %q", raw)
+		}
+		sum += v
+	}
+	return sum, len(pairs), nil
+}
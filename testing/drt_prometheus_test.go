@@ -0,0 +1,178 @@
+package testing
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDRTPrometheus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="get",code="200"} 1027 1395066363000
+http_requests_total{method="get",code="500"} 3
+http_requests_total{method="post",code="200"} 12
+# TYPE up gauge
+up 1
+process_note{text="a \"quoted\", value"} 2.5
+`))
+	})
+	mux.HandleFunc("/api/v1/query", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		switch {
+		case query == `sum(up{job="api"})`:
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1435781451.781,"2"]}]}}`))
+		case strings.HasPrefix(query, "scalar("):
+			w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1435781451.781,"0.25"]}}`))
+		default:
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Run("scrape pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_prometheus" "test" {
+  url = "%s/metrics"
+
+  metric "gets" {
+    name   = "http_requests_total"
+    labels = {
+      method = "get"
+    }
+    equal = 1030
+  }
+  metric "errors" {
+    name = "http_requests_total"
+    labels = {
+      code = "500"
+    }
+    max = 5
+  }
+  metric "up" {
+    name = "up"
+    min  = 1
+  }
+  metric "note" {
+    name = "process_note"
+    labels = {
+      text = "a \"quoted\", value"
+    }
+  }
+}
+
+data "testing_assertions" "test" {
+  equal "values" {
+	got  = data.testing_prometheus.test.values
+	want = tomap({
+	  errors = 3
+	  gets   = 1030
+	  note   = 2.5
+	  up     = 1
+	})
+  }
+}
+`, server.URL))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("scrape threshold fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_prometheus" "test" {
+  url = "%s/metrics"
+
+  metric "errors" {
+    name = "http_requests_total"
+    labels = {
+      code = "500"
+    }
+    statement = "has few server errors"
+    max       = 0
+  }
+}
+`, server.URL))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("scrape missing metric", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_prometheus" "test" {
+  url = "%s/metrics"
+
+  metric "missing" {
+    name = "nonexistent_total"
+  }
+}
+`, server.URL))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("query pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_prometheus" "test" {
+  prometheus_url = %q
+
+  metric "up" {
+    name   = "up"
+    labels = {
+      job = "api"
+    }
+    equal = 2
+  }
+  metric "error_ratio" {
+    query = "scalar(sum(rate(http_requests_total{code=\"500\"}[5m])))"
+    max   = 0.5
+  }
+}
+`, server.URL))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("query no results", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_prometheus" "test" {
+  prometheus_url = %q
+
+  metric "down" {
+    query = "up == 0"
+  }
+}
+`, server.URL))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
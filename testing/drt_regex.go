@@ -0,0 +1,91 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type regexDRT struct {
+	Input     string  `cty:"input"`
+	Pattern   string  `cty:"pattern"`
+	Subject   *string `cty:"subject"`
+	Statement *string `cty:"statement"`
+
+	Match    *string            `cty:"match"`
+	Groups   map[string]*string `cty:"groups"`
+	Captures []*string          `cty:"captures"`
+}
+
+func regexDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_regex", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"input":     {Type: cty.String, Required: true},
+				"pattern":   {Type: cty.String, Required: true, ValidateFn: validateRegexp},
+				"subject":   {Type: cty.String, Optional: true},
+				"statement": {Type: cty.String, Optional: true},
+
+				"match":    {Type: cty.String, Computed: true},
+				"groups":   {Type: cty.Map(cty.String), Computed: true},
+				"captures": {Type: cty.List(cty.String), Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *regexDRT) (*regexDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			// The pattern was already checked by validateRegexp.
+			re := regexp.MustCompile(obj.Pattern)
+			loc := re.FindStringSubmatchIndex(obj.Input)
+			if loc == nil {
+				statement := "input matches the pattern"
+				if obj.Statement != nil {
+					statement = *obj.Statement
+				}
+				if obj.Subject != nil {
+					statement = fmt.Sprintf("%s %s", *obj.Subject, statement)
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail: fmt.Sprintf(
+						"Assertion failed: %s.\n  Want input matching: %s\n  Got input:           %s",
+						statement,
+						formatValue(cty.StringVal(obj.Pattern), 2),
+						formatValue(cty.StringVal(obj.Input), 2),
+					),
+					Path: cty.Path(nil).GetAttr("input"),
+				})
+				return obj, diags
+			}
+
+			match := obj.Input[loc[0]:loc[1]]
+			obj.Match = &match
+
+			// Groups that didn't participate in the match, such as an
+			// optional group, are represented as null rather than as an
+			// empty string, so that the two situations can be distinguished.
+			names := re.SubexpNames()
+			obj.Groups = make(map[string]*string)
+			obj.Captures = make([]*string, 0, len(names)-1)
+			for i := 1; i < len(names); i++ {
+				var v *string
+				if start, end := loc[i*2], loc[i*2+1]; start >= 0 {
+					s := obj.Input[start:end]
+					v = &s
+				}
+				obj.Captures = append(obj.Captures, v)
+				if names[i] != "" {
+					obj.Groups[names[i]] = v
+				}
+			}
+
+			return obj, diags
+		},
+	})
+}
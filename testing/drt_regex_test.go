@@ -0,0 +1,57 @@
+package testing
+
+import (
+	"testing"
+)
+
+func TestDRTRegex(t *testing.T) {
+	t.Run("match", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_regex" "test" {
+  input   = "Server: nginx/1.15.8 (Ubuntu)"
+  pattern = "(?P<name>[a-z]+)/(?P<version>[0-9.]+)(?: \\((?P<os>[^)]+)\\))?( beta)?"
+}
+
+data "testing_assertions" "test" {
+  equal "match" {
+    got  = data.testing_regex.test.match
+    want = "nginx/1.15.8 (Ubuntu)"
+  }
+  equal "groups" {
+    got = data.testing_regex.test.groups
+    want = tomap({
+      name    = "nginx"
+      version = "1.15.8"
+      os      = "Ubuntu"
+    })
+  }
+  check "unmatched_capture" {
+    expect = data.testing_regex.test.captures[3] == null
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("no match", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_regex" "test" {
+  input   = "Server: Apache"
+  pattern = "nginx/(?P<version>[0-9.]+)"
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type retryDRT struct {
+	Program     []string          `cty:"program"`
+	Environment map[string]string `cty:"environment"`
+	HTTP        *retryDRTHTTP     `cty:"http"`
+
+	Timeout     *string `cty:"timeout"`
+	Interval    *string `cty:"interval"`
+	MaxInterval *string `cty:"max_interval"`
+
+	Attempts  *int    `cty:"attempts"`
+	LastError *string `cty:"last_error"`
+}
+
+type retryDRTHTTP struct {
+	URL        string  `cty:"url"`
+	Method     *string `cty:"method"`
+	StatusCode *int    `cty:"status_code"`
+}
+
+func retryDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_retry", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"program": {
+					Type:       cty.List(cty.String),
+					Optional:   true,
+					ValidateFn: validateProgram,
+				},
+				"environment": {
+					Type:     cty.Map(cty.String),
+					Optional: true,
+				},
+
+				"timeout":      {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"interval":     {Type: cty.String, Optional: true, ValidateFn: validatePositiveDuration},
+				"max_interval": {Type: cty.String, Optional: true, ValidateFn: validatePositiveDuration},
+
+				"attempts":   {Type: cty.Number, Computed: true},
+				"last_error": {Type: cty.String, Computed: true},
+			},
+			NestedBlockTypes: map[string]*tfschema.NestedBlockType{
+				"http": {
+					Nesting: tfschema.NestingSingle,
+					Content: tfschema.BlockType{
+						Attributes: map[string]*tfschema.Attribute{
+							"url":         {Type: cty.String, Required: true},
+							"method":      {Type: cty.String, Optional: true},
+							"status_code": {Type: cty.Number, Optional: true},
+						},
+					},
+				},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *retryDRT) (*retryDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			if (obj.Program == nil) == (obj.HTTP == nil) {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid retry configuration",
+					Detail:   "Exactly one of the \"program\" argument or a nested \"http\" block must be set, to specify what to retry.",
+				})
+				return obj, diags
+			}
+
+			timeout := client.timeout(obj.Timeout, 5*time.Minute)
+			interval := durationOrDefault(obj.Interval, 1*time.Second)
+			maxInterval := durationOrDefault(obj.MaxInterval, 30*time.Second)
+
+			var try func(ctx context.Context) error
+			var what string
+			if obj.Program != nil {
+				try = func(ctx context.Context) error {
+					return retryProgram(ctx, client, obj.Program, obj.Environment)
+				}
+				what = "program"
+			} else {
+				method := "GET"
+				if obj.HTTP.Method != nil {
+					method = *obj.HTTP.Method
+				}
+				statusCode := 200
+				if obj.HTTP.StatusCode != nil {
+					statusCode = *obj.HTTP.StatusCode
+				}
+				try = func(ctx context.Context) error {
+					return retryHTTP(ctx, method, obj.HTTP.URL, statusCode)
+				}
+				what = "HTTP request"
+			}
+
+			start := time.Now()
+			deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			attempts := 0
+			var lastErr error
+			for {
+				attempts++
+				err := try(deadlineCtx)
+				if err == nil {
+					break
+				}
+				if deadlineCtx.Err() == nil || lastErr == nil {
+					// If the attempt was cut short by our deadline then we'll
+					// retain the previous error, because it's probably more
+					// useful than a message about the process being killed.
+					lastErr = err
+				}
+
+				select {
+				case <-time.After(interval):
+				case <-deadlineCtx.Done():
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   fmt.Sprintf("The %s did not succeed after %d attempts over %s. The last attempt failed: %s.", what, attempts, time.Since(start).Round(time.Second), lastErr),
+					})
+					obj.Attempts = &attempts
+					lastErrMsg := lastErr.Error()
+					obj.LastError = &lastErrMsg
+					return obj, diags
+				}
+
+				interval *= 2
+				if interval > maxInterval {
+					interval = maxInterval
+				}
+			}
+
+			obj.Attempts = &attempts
+			obj.LastError = nil
+			if lastErr != nil {
+				lastErrMsg := lastErr.Error()
+				obj.LastError = &lastErrMsg
+			}
+			return obj, diags
+		},
+	}, ownTimeout)
+}
+
+func retryProgram(ctx context.Context, client *Client, program []string, environment map[string]string) error {
+	cmd := programCommand(ctx, client, program, environment)
+	var errBuf bytes.Buffer
+	cmd.Stderr = &errBuf
+
+	release, err := client.acquireProgram(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	err = cmd.Run()
+	if err != nil {
+		if msg := strings.TrimSpace(errBuf.String()); msg != "" {
+			return fmt.Errorf("%s; the program produced the following error messages:\n  %s", err, strings.Replace(msg, "\n", "\n  ", -1))
+		}
+		return err
+	}
+	return nil
+}
+
+func retryHTTP(ctx context.Context, method, url string, wantStatus int) error {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != wantStatus {
+		return fmt.Errorf("server returned %q, but want status code %d", resp.Status, wantStatus)
+	}
+	return nil
+}
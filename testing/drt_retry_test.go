@@ -0,0 +1,87 @@
+package testing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDRTRetry(t *testing.T) {
+	t.Run("eventually succeeds", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_retry" "test" {
+  program  = ["sh", "-c", "test -f marker || { touch marker; echo not yet >&2; exit 1; }"]
+  interval = "10ms"
+}
+
+data "testing_assertions" "test" {
+  equal "attempts" {
+	got  = data.testing_retry.test.attempts
+	want = 2
+  }
+  check "last_error" {
+	expect = length(regexall("not yet", data.testing_retry.test.last_error)) > 0
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("deadline exceeded", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_retry" "test" {
+  program  = ["false"]
+  timeout  = "500ms"
+  interval = "10ms"
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("zero interval", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_retry" "test" {
+  program  = ["false"]
+  timeout  = "1s"
+  interval = "0s"
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Fatal("succeeded; want error")
+		}
+		if !strings.Contains(err.Error(), "must be greater than zero") {
+			t.Errorf("error does not reject the interval\n%s", err)
+		}
+	})
+	t.Run("neither program nor http", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_retry" "test" {
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
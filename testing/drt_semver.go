@@ -0,0 +1,106 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	version "github.com/hashicorp/go-version"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type semverDRT struct {
+	Version    string  `cty:"version"`
+	Constraint *string `cty:"constraint"`
+	Subject    *string `cty:"subject"`
+
+	Major      *int    `cty:"major"`
+	Minor      *int    `cty:"minor"`
+	Patch      *int    `cty:"patch"`
+	Prerelease *string `cty:"prerelease"`
+	Metadata   *string `cty:"metadata"`
+	Normalized *string `cty:"normalized"`
+}
+
+func semverDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_semver", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"version": {Type: cty.String, Required: true},
+				"constraint": {
+					Type:     cty.String,
+					Optional: true,
+					ValidateFn: func(v string) tfsdk.Diagnostics {
+						var diags tfsdk.Diagnostics
+						if _, err := version.NewConstraint(v); err != nil {
+							diags = diags.Append(tfsdk.ValidationError(
+								cty.Path(nil).NewErrorf("must be a valid version constraint: %s", err),
+							))
+						}
+						return diags
+					},
+				},
+				"subject": {Type: cty.String, Optional: true},
+
+				"major":      {Type: cty.Number, Computed: true},
+				"minor":      {Type: cty.Number, Computed: true},
+				"patch":      {Type: cty.Number, Computed: true},
+				"prerelease": {Type: cty.String, Computed: true},
+				"metadata":   {Type: cty.String, Computed: true},
+				"normalized": {Type: cty.String, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *semverDRT) (*semverDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			// Version strings often come from files or command output that
+			// has a trailing newline, so we tolerate surrounding whitespace.
+			v, err := version.NewVersion(strings.TrimSpace(obj.Version))
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid version",
+					Detail:   fmt.Sprintf("Cannot parse %q as a version number: %s.", obj.Version, err),
+					Path:     cty.Path(nil).GetAttr("version"),
+				})
+				return obj, diags
+			}
+
+			segs := v.Segments()
+			prerelease := v.Prerelease()
+			metadata := v.Metadata()
+			normalized := v.String()
+			obj.Major, obj.Minor, obj.Patch = &segs[0], &segs[1], &segs[2]
+			obj.Prerelease = &prerelease
+			obj.Metadata = &metadata
+			obj.Normalized = &normalized
+
+			if obj.Constraint != nil {
+				// The constraint was already checked by ValidateFn.
+				constraints, _ := version.NewConstraint(*obj.Constraint)
+				if !constraints.Check(v) {
+					statement := "version meets the constraint"
+					if obj.Subject != nil {
+						statement = fmt.Sprintf("%s version meets the constraint", *obj.Subject)
+					}
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail: fmt.Sprintf(
+							"Assertion failed: %s.\n  Want version matching: %s\n  Got version:           %s",
+							statement,
+							formatValue(cty.StringVal(constraints.String()), 2),
+							formatValue(cty.StringVal(normalized), 2),
+						),
+						Path: cty.Path(nil).GetAttr("constraint"),
+					})
+				}
+			}
+
+			return obj, diags
+		},
+	})
+}
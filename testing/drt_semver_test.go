@@ -0,0 +1,86 @@
+package testing
+
+import (
+	"testing"
+)
+
+func TestDRTSemver(t *testing.T) {
+	t.Run("pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_semver" "test" {
+  version    = "v1.4.2-beta.1+build.5\n"
+  constraint = ">= 1.4.2-alpha"
+}
+
+data "testing_assertions" "test" {
+  equal "components" {
+    got = [
+      data.testing_semver.test.major,
+      data.testing_semver.test.minor,
+      data.testing_semver.test.patch,
+      data.testing_semver.test.prerelease,
+      data.testing_semver.test.metadata,
+    ]
+    want = [1, 4, 2, "beta.1", "build.5"]
+  }
+  equal "normalized" {
+    got  = data.testing_semver.test.normalized
+    want = "1.4.2-beta.1+build.5"
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("pessimistic", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_semver" "test" {
+  version    = "2.3"
+  constraint = "~> 2.1"
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("constraint fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_semver" "test" {
+  version    = "2.0.0"
+  constraint = "~> 1.4"
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("invalid version", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_semver" "test" {
+  version = "not a version"
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
@@ -0,0 +1,291 @@
+package testing
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/textproto"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type smtpDRT struct {
+	Address  string  `cty:"address"`
+	HeloName *string `cty:"helo_name"`
+	Timeout  *string `cty:"timeout"`
+	StartTLS *bool   `cty:"starttls"`
+	Subject  *string `cty:"subject"`
+
+	TLS                *bool   `cty:"tls"`
+	TLSServerName      *string `cty:"tls_server_name"`
+	CACertificatePEM   *string `cty:"ca_certificate_pem"`
+	InsecureSkipVerify *bool   `cty:"insecure_skip_verify"`
+
+	BannerPattern    *string  `cty:"banner_pattern"`
+	ExpectExtensions []string `cty:"expect_extensions"`
+
+	Banner     *string           `cty:"banner"`
+	Extensions map[string]string `cty:"extensions"`
+	TLSVersion *string           `cty:"tls_version"`
+}
+
+func smtpDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_smtp", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"address":   {Type: cty.String, Required: true},
+				"helo_name": {Type: cty.String, Optional: true},
+				"timeout":   {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"starttls":  {Type: cty.Bool, Optional: true},
+				"subject":   {Type: cty.String, Optional: true},
+
+				"tls":                  {Type: cty.Bool, Optional: true},
+				"tls_server_name":      {Type: cty.String, Optional: true},
+				"ca_certificate_pem":   {Type: cty.String, Optional: true},
+				"insecure_skip_verify": {Type: cty.Bool, Optional: true},
+
+				"banner_pattern":    {Type: cty.String, Optional: true, ValidateFn: validateRegexp},
+				"expect_extensions": {Type: cty.List(cty.String), Optional: true},
+
+				"banner":      {Type: cty.String, Computed: true},
+				"extensions":  {Type: cty.Map(cty.String), Computed: true},
+				"tls_version": {Type: cty.String, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *smtpDRT) (*smtpDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			startTLS := obj.StartTLS != nil && *obj.StartTLS
+			implicitTLS := obj.TLS != nil && *obj.TLS
+			if startTLS && implicitTLS {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Conflicting TLS arguments",
+					Detail:   "At most one of the \"tls\" and \"starttls\" arguments may be set to true. Use \"tls\" for a server that expects TLS as soon as the connection is opened, or \"starttls\" for a server that upgrades a plaintext connection.",
+				})
+				return obj, diags
+			}
+
+			host, _, err := net.SplitHostPort(obj.Address)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid SMTP address",
+					Detail:   fmt.Sprintf("The \"address\" argument must be a host and port, like \"mail.example.com:25\": %s.", err),
+					Path:     cty.Path(nil).GetAttr("address"),
+				})
+				return obj, diags
+			}
+			tlsConfig := &tls.Config{ServerName: host}
+			if obj.TLSServerName != nil {
+				tlsConfig.ServerName = *obj.TLSServerName
+			}
+			if obj.InsecureSkipVerify != nil {
+				tlsConfig.InsecureSkipVerify = *obj.InsecureSkipVerify
+			}
+			if obj.CACertificatePEM != nil {
+				pool := x509.NewCertPool()
+				if !pool.AppendCertsFromPEM([]byte(*obj.CACertificatePEM)) {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Invalid CA certificate",
+						Detail:   "The given CA certificate does not contain any valid PEM-encoded certificates.",
+						Path:     cty.Path(nil).GetAttr("ca_certificate_pem"),
+					})
+					return obj, diags
+				}
+				tlsConfig.RootCAs = pool
+			}
+
+			heloName := "localhost"
+			if obj.HeloName != nil {
+				heloName = *obj.HeloName
+			}
+			subject := "SMTP server"
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+			deadline := time.Now().Add(client.timeout(obj.Timeout, 10*time.Second))
+
+			fail := func(summary string, err error) {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  summary,
+					Detail:   fmt.Sprintf("Error communicating with %s: %s.", obj.Address, err),
+					Path:     cty.Path(nil).GetAttr("address"),
+				})
+			}
+
+			dialer := &net.Dialer{Deadline: deadline}
+			var conn net.Conn
+			if implicitTLS {
+				conn, err = tls.DialWithDialer(dialer, "tcp", obj.Address, tlsConfig)
+			} else {
+				conn, err = dialer.DialContext(ctx, "tcp", obj.Address)
+			}
+			if err != nil {
+				fail("SMTP connection failed", err)
+				return obj, diags
+			}
+			defer conn.Close()
+			conn.SetDeadline(deadline)
+
+			text := textproto.NewConn(conn)
+			_, banner, err := text.ReadResponse(220)
+			if err != nil {
+				fail("SMTP connection failed", fmt.Errorf("unexpected greeting: %s", err))
+				return obj, diags
+			}
+			obj.Banner = &banner
+
+			exts, err := smtpEHLO(text, heloName)
+			if err != nil {
+				fail("SMTP EHLO failed", err)
+				return obj, diags
+			}
+
+			if startTLS {
+				if _, ok := exts["STARTTLS"]; !ok {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   fmt.Sprintf("Assertion failed: %s supports STARTTLS.\n  The server did not advertise the STARTTLS extension in its EHLO response.", subject),
+						Path:     cty.Path(nil).GetAttr("starttls"),
+					})
+					return obj, diags
+				}
+				id, err := text.Cmd("STARTTLS")
+				if err == nil {
+					text.StartResponse(id)
+					_, _, err = text.ReadResponse(220)
+					text.EndResponse(id)
+				}
+				if err != nil {
+					fail("SMTP STARTTLS failed", err)
+					return obj, diags
+				}
+				tlsConn := tls.Client(conn, tlsConfig)
+				if err := tlsConn.Handshake(); err != nil {
+					fail("SMTP STARTTLS failed", fmt.Errorf("TLS handshake failed: %s", err))
+					return obj, diags
+				}
+				conn = tlsConn
+				text = textproto.NewConn(conn)
+
+				// The extensions offered can change once the connection is
+				// secure, so we must ask again.
+				exts, err = smtpEHLO(text, heloName)
+				if err != nil {
+					fail("SMTP EHLO failed", err)
+					return obj, diags
+				}
+			}
+			if tlsConn, ok := conn.(*tls.Conn); ok {
+				version := tlsVersionName(tlsConn.ConnectionState().Version)
+				obj.TLSVersion = &version
+			}
+			obj.Extensions = exts
+
+			// We're done, so we'll politely end the session. The server's
+			// reply doesn't affect the result.
+			if id, err := text.Cmd("QUIT"); err == nil {
+				text.StartResponse(id)
+				text.ReadResponse(221)
+				text.EndResponse(id)
+			}
+
+			if obj.BannerPattern != nil {
+				// The pattern was already checked by validateRegexp.
+				re := regexp.MustCompile(*obj.BannerPattern)
+				if !re.MatchString(banner) {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   fmt.Sprintf("Assertion failed: %s has the expected banner.\n  Want banner matching: %s\n  Got banner:           %s", subject, formatValue(cty.StringVal(*obj.BannerPattern), 2), formatValue(cty.StringVal(banner), 2)),
+						Path:     cty.Path(nil).GetAttr("banner_pattern"),
+					})
+				}
+			}
+			var missing []string
+			for _, name := range obj.ExpectExtensions {
+				if _, ok := exts[strings.ToUpper(name)]; !ok {
+					missing = append(missing, name)
+				}
+			}
+			if len(missing) != 0 {
+				offered := make([]string, 0, len(exts))
+				for name := range exts {
+					offered = append(offered, name)
+				}
+				sort.Strings(offered)
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   fmt.Sprintf("Assertion failed: %s supports the expected extensions.\n  Missing:  %s\n  Offered:  %s", subject, strings.Join(missing, ", "), strings.Join(offered, ", ")),
+					Path:     cty.Path(nil).GetAttr("expect_extensions"),
+				})
+			}
+
+			return obj, diags
+		},
+	}, ownTimeout)
+}
+
+// smtpEHLO sends an EHLO command and returns the extensions the server
+// advertises in its response, as a map from the upper-case extension keyword
+// to any parameters given after it.
+func smtpEHLO(text *textproto.Conn, heloName string) (map[string]string, error) {
+	id, err := text.Cmd("EHLO %s", heloName)
+	if err != nil {
+		return nil, err
+	}
+	text.StartResponse(id)
+	defer text.EndResponse(id)
+	_, msg, err := text.ReadResponse(250)
+	if err != nil {
+		return nil, err
+	}
+
+	// The first line of the response is the server's greeting, and each
+	// further line describes one extension.
+	exts := make(map[string]string)
+	lines := strings.Split(msg, "\n")
+	for _, line := range lines[1:] {
+		parts := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if parts[0] == "" {
+			continue
+		}
+		params := ""
+		if len(parts) > 1 {
+			params = parts[1]
+		}
+		exts[strings.ToUpper(parts[0])] = params
+	}
+	return exts, nil
+}
+
+// tlsVersionName returns the conventional name for the given TLS protocol
+// version number.
+func tlsVersionName(v uint16) string {
+	switch v {
+	case tls.VersionTLS10:
+		return "TLSv1.0"
+	case tls.VersionTLS11:
+		return "TLSv1.1"
+	case tls.VersionTLS12:
+		return "TLSv1.2"
+	case tls.VersionTLS13:
+		return "TLSv1.3"
+	default:
+		return fmt.Sprintf("0x%04x", v)
+	}
+}
@@ -0,0 +1,156 @@
+package testing
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testSMTPServer is a minimal SMTP server that supports just enough of the
+// protocol for testing_smtp: EHLO, STARTTLS and QUIT.
+func testSMTPServer(t *testing.T, startTLS bool) (addr string, close func()) {
+	// We borrow the self-signed certificate that httptest uses for its
+	// TLS servers.
+	certSrv := httptest.NewUnstartedServer(nil)
+	certSrv.StartTLS()
+	tlsConfig := &tls.Config{Certificates: certSrv.TLS.Certificates}
+	certSrv.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				secure := false
+				rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+				reply := func(lines ...string) {
+					for _, line := range lines {
+						rw.WriteString(line + "\r\n")
+					}
+					rw.Flush()
+				}
+				reply("220 mail.example.com ESMTP Testfix")
+				for {
+					line, err := rw.ReadString('\n')
+					if err != nil {
+						return
+					}
+					switch cmd := strings.ToUpper(strings.Fields(line)[0]); {
+					case cmd == "EHLO" && startTLS && !secure:
+						reply("250-mail.example.com", "250-SIZE 10240000", "250 STARTTLS")
+					case cmd == "EHLO":
+						reply("250-mail.example.com", "250-SIZE 10240000", "250-8BITMIME", "250 AUTH PLAIN LOGIN")
+					case cmd == "STARTTLS" && startTLS && !secure:
+						reply("220 Ready to start TLS")
+						conn = tls.Server(conn, tlsConfig)
+						rw = bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+						secure = true
+					case cmd == "QUIT":
+						reply("221 Bye")
+						return
+					default:
+						reply("502 Command not implemented")
+					}
+				}
+			}(conn)
+		}
+	}()
+	return ln.Addr().String(), func() { ln.Close() }
+}
+
+func TestDRTSMTP(t *testing.T) {
+	t.Run("plaintext", func(t *testing.T) {
+		addr, close := testSMTPServer(t, false)
+		defer close()
+
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_smtp" "test" {
+  address = %q
+
+  banner_pattern    = "ESMTP"
+  expect_extensions = ["8bitmime", "AUTH"]
+}
+
+data "testing_assertions" "test" {
+  equal "auth" {
+    got  = data.testing_smtp.test.extensions["AUTH"]
+    want = "PLAIN LOGIN"
+  }
+  check "tls" {
+    expect = data.testing_smtp.test.tls_version == null
+  }
+}
+`, addr))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("starttls", func(t *testing.T) {
+		addr, close := testSMTPServer(t, true)
+		defer close()
+
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_smtp" "test" {
+  address  = %q
+  starttls = true
+
+  insecure_skip_verify = true
+  expect_extensions    = ["AUTH"]
+}
+
+data "testing_assertions" "test" {
+  check "tls" {
+    expect = data.testing_smtp.test.tls_version != null
+  }
+}
+`, addr))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("fail", func(t *testing.T) {
+		addr, close := testSMTPServer(t, false)
+		defer close()
+
+		for name, args := range map[string]string{
+			"banner":     `banner_pattern = "Postfix"`,
+			"extensions": `expect_extensions = ["PIPELINING"]`,
+			"starttls":   `starttls = true`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				wd := testHelper.RequireNewWorkingDir(t)
+				defer wd.Close()
+
+				wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_smtp" "test" {
+  address = %q
+  %s
+}
+`, addr, args))
+
+				wd.RequireInit(t)
+				err := wd.Apply()
+				if err == nil {
+					t.Error("succeeded; want error")
+				}
+			})
+		}
+	})
+}
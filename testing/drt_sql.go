@@ -0,0 +1,272 @@
+package testing
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+
+	// These register the database/sql drivers listed in sqlDrivers. The
+	// sqlite3 driver is registered in sql_sqlite.go, because it needs cgo.
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+)
+
+type sqlDRT struct {
+	Driver  string   `cty:"driver"`
+	DSN     string   `cty:"dsn"`
+	Query   string   `cty:"query"`
+	Args    []string `cty:"args"`
+	Subject *string  `cty:"subject"`
+
+	RowCount *int      `cty:"row_count"`
+	Values   cty.Value `cty:"value"`
+
+	Columns []string             `cty:"columns"`
+	Rows    []map[string]*string `cty:"rows"`
+}
+
+type sqlDRTValue struct {
+	Row       *int    `cty:"row"`
+	Column    string  `cty:"column"`
+	Want      string  `cty:"want"`
+	Statement *string `cty:"statement"`
+}
+
+// sqlDrivers are the database/sql driver names that testing_sql accepts.
+var sqlDrivers = map[string]bool{
+	"mysql":    true,
+	"postgres": true,
+	"sqlite3":  true,
+}
+
+func sqlDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_sql", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"driver": {
+					Type:     cty.String,
+					Required: true,
+					ValidateFn: func(v string) tfsdk.Diagnostics {
+						var diags tfsdk.Diagnostics
+						if !sqlDrivers[v] {
+							diags = diags.Append(tfsdk.ValidationError(
+								cty.Path(nil).NewErrorf("must be one of \"mysql\", \"postgres\", or \"sqlite3\""),
+							))
+						}
+						return diags
+					},
+				},
+				"dsn":     {Type: cty.String, Required: true, Sensitive: true},
+				"query":   {Type: cty.String, Required: true},
+				"args":    {Type: cty.List(cty.String), Optional: true},
+				"subject": {Type: cty.String, Optional: true},
+
+				"row_count": {Type: cty.Number, Optional: true},
+
+				"columns": {Type: cty.List(cty.String), Computed: true},
+				"rows":    {Type: cty.List(cty.Map(cty.String)), Computed: true},
+			},
+			NestedBlockTypes: map[string]*tfschema.NestedBlockType{
+				"value": {
+					Nesting: tfschema.NestingMap,
+					Content: tfschema.BlockType{
+						Attributes: map[string]*tfschema.Attribute{
+							"row":       {Type: cty.Number, Optional: true},
+							"column":    {Type: cty.String, Required: true},
+							"want":      {Type: cty.String, Required: true},
+							"statement": {Type: cty.String, Optional: true},
+						},
+					},
+				},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *sqlDRT) (*sqlDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			subject := ""
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+
+			if obj.Driver == "sqlite3" && !sqliteAvailable {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "SQLite driver not available",
+					Detail:   "This build of the testing provider doesn't include the sqlite3 driver, because it was built without cgo. Use a build of the provider that was compiled with CGO_ENABLED=1.",
+					Path:     cty.Path(nil).GetAttr("driver"),
+				})
+				return obj, diags
+			}
+
+			db, err := sql.Open(obj.Driver, obj.DSN)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid database connection string",
+					Detail:   fmt.Sprintf("Cannot use the given connection string with the %s driver: %s.", obj.Driver, err),
+					Path:     cty.Path(nil).GetAttr("dsn"),
+				})
+				return obj, diags
+			}
+			defer db.Close()
+
+			args := make([]interface{}, len(obj.Args))
+			for i, arg := range obj.Args {
+				args[i] = arg
+			}
+			columns, rows, err := sqlQuery(ctx, db, obj.Query, args)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Query failed",
+					Detail:   fmt.Sprintf("Error running query: %s.", err),
+					Path:     cty.Path(nil).GetAttr("query"),
+				})
+				return obj, diags
+			}
+			obj.Columns = columns
+			obj.Rows = rows
+
+			if obj.RowCount != nil && *obj.RowCount != len(rows) {
+				statement := "query returns the expected number of rows"
+				if subject != "" {
+					statement = fmt.Sprintf("%s has the expected number of rows", subject)
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   namedAssertionFailureMsg(statement, "count", cty.NumberIntVal(int64(*obj.RowCount)), cty.NumberIntVal(int64(len(rows)))),
+					Path:     cty.Path(nil).GetAttr("row_count"),
+				})
+			}
+
+			for it := obj.Values.ElementIterator(); it.Next(); {
+				if client.failedFast(diags) {
+					break
+				}
+				k, v := it.Element()
+				var val sqlDRTValue
+				err := gocty.FromCtyValue(v, &val)
+				if err != nil {
+					// Should never happen; indicates that our struct is wrong.
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Bug in 'testing' provider",
+						Detail:   fmt.Sprintf("The provider encountered a problem while decoding the value %q block: %s.\n\nThis is a bug in the provider; please report it in the provider's issue tracker.", k.AsString(), err),
+					})
+					continue
+				}
+				path := cty.Path(nil).GetAttr("value").Index(k)
+
+				rowIdx := 0
+				if val.Row != nil {
+					rowIdx = *val.Row
+				}
+				if rowIdx < 0 || rowIdx >= len(rows) {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   fmt.Sprintf("The query returned %d rows, so there is no row with index %d.", len(rows), rowIdx),
+						Path:     path.GetAttr("row"),
+					})
+					continue
+				}
+				row := rows[rowIdx]
+				got, exists := row[val.Column]
+				if !exists {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Invalid column name",
+						Detail:   fmt.Sprintf("The query result has no column named %q. The available columns are: %s.", val.Column, strings.Join(columns, ", ")),
+						Path:     path.GetAttr("column"),
+					})
+					continue
+				}
+
+				if got != nil && *got == val.Want {
+					continue
+				}
+
+				statement := ""
+				if val.Statement != nil {
+					if subject != "" {
+						statement = fmt.Sprintf("%s %s", subject, *val.Statement)
+					} else {
+						statement = *val.Statement
+					}
+				}
+				gotVal := cty.NullVal(cty.String)
+				if got != nil {
+					gotVal = cty.StringVal(*got)
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   namedAssertionFailureMsg(statement, "value", cty.StringVal(val.Want), gotVal),
+					Path:     path.GetAttr("want"),
+				})
+			}
+
+			return obj, diags
+		},
+	})
+}
+
+// sqlQuery runs the given query and returns the names of its result columns
+// along with all of its rows, with each value converted to a string. NULL
+// values are represented as nil.
+func sqlQuery(ctx context.Context, db *sql.DB, query string, args []interface{}) ([]string, []map[string]*string, error) {
+	rs, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rs.Close()
+
+	columns, err := rs.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	seen := make(map[string]bool, len(columns))
+	for _, name := range columns {
+		if seen[name] {
+			return nil, nil, fmt.Errorf("the result has more than one column named %q; use column aliases to give each column a unique name", name)
+		}
+		seen[name] = true
+	}
+
+	rows := []map[string]*string{}
+	for rs.Next() {
+		vals := make([]sql.NullString, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rs.Scan(ptrs...); err != nil {
+			return nil, nil, err
+		}
+		row := make(map[string]*string, len(columns))
+		for i, name := range columns {
+			if vals[i].Valid {
+				s := vals[i].String
+				row[name] = &s
+			} else {
+				row[name] = nil
+			}
+		}
+		rows = append(rows, row)
+	}
+	if err := rs.Err(); err != nil {
+		return nil, nil, err
+	}
+	if columns == nil {
+		columns = []string{}
+	}
+	return columns, rows, nil
+}
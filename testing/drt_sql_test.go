@@ -0,0 +1,183 @@
+package testing
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDRTSQL(t *testing.T) {
+	if !sqliteAvailable {
+		t.Skip("the sqlite3 driver requires cgo")
+	}
+
+	dir, err := ioutil.TempDir("", "tf-testing-sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "test.db")
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`
+CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, email TEXT);
+INSERT INTO users (id, name, email) VALUES (1, 'Alice', 'alice@example.com');
+INSERT INTO users (id, name, email) VALUES (2, 'Bob', NULL);
+`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("value pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_sql" "test" {
+  driver = "sqlite3"
+  dsn    = %q
+  query  = "SELECT id, name, email FROM users ORDER BY id"
+
+  row_count = 2
+
+  value "first_name" {
+    column = "name"
+    want   = "Alice"
+  }
+  value "second_id" {
+    row    = 1
+    column = "id"
+    want   = "2"
+  }
+}
+
+data "testing_assertions" "test" {
+  equal "columns" {
+	got  = data.testing_sql.test.columns
+	want = tolist(["id", "name", "email"])
+  }
+  check "null_email" {
+	expect = data.testing_sql.test.rows[1]["email"] == null
+  }
+}
+`, dbPath))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("args", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_sql" "test" {
+  driver = "sqlite3"
+  dsn    = %q
+  query  = "SELECT count(*) AS n FROM users WHERE name = ?"
+  args   = ["Bob"]
+
+  value "count" {
+    column = "n"
+    want   = "1"
+  }
+}
+`, dbPath))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("value fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_sql" "test" {
+  driver = "sqlite3"
+  dsn    = %q
+  query  = "SELECT email FROM users WHERE id = 2"
+
+  value "email" {
+    column = "email"
+    want   = "bob@example.com"
+  }
+}
+`, dbPath))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("row count fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_sql" "test" {
+  driver    = "sqlite3"
+  dsn       = %q
+  query     = "SELECT * FROM users"
+  row_count = 3
+}
+`, dbPath))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("query error", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_sql" "test" {
+  driver = "sqlite3"
+  dsn    = %q
+  query  = "SELECT * FROM nonexistent"
+}
+`, dbPath))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
+
+func TestDRTSQLWithoutSQLite(t *testing.T) {
+	if sqliteAvailable {
+		t.Skip("the sqlite3 driver is available in cgo builds")
+	}
+
+	wd := testHelper.RequireNewWorkingDir(t)
+	defer wd.Close()
+
+	wd.RequireSetConfig(t, `
+data "testing_sql" "test" {
+  driver = "sqlite3"
+  dsn    = "test.db"
+  query  = "SELECT 1"
+}
+`)
+
+	wd.RequireInit(t)
+	err := wd.Apply()
+	if err == nil {
+		t.Fatal("apply succeeded; want error")
+	}
+	if got, want := err.Error(), "SQLite driver not available"; !strings.Contains(got, want) {
+		t.Errorf("error does not mention %q\n%s", want, got)
+	}
+}
@@ -0,0 +1,267 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/crypto/ssh"
+)
+
+type sshDRT struct {
+	Host       string  `cty:"host"`
+	Port       *int    `cty:"port"`
+	User       string  `cty:"user"`
+	Password   *string `cty:"password"`
+	PrivateKey *string `cty:"private_key"`
+	HostKey    *string `cty:"host_key"`
+
+	Timeout *string `cty:"timeout"`
+
+	Command          string  `cty:"command"`
+	ExpectedExitCode *int    `cty:"expected_exit_code"`
+	StdoutPattern    *string `cty:"stdout_pattern"`
+	StderrPattern    *string `cty:"stderr_pattern"`
+
+	ExitCode *int    `cty:"exit_code"`
+	Stdout   *string `cty:"stdout"`
+	Stderr   *string `cty:"stderr"`
+}
+
+func sshDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_ssh", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"host":        {Type: cty.String, Required: true},
+				"port":        {Type: cty.Number, Optional: true},
+				"user":        {Type: cty.String, Required: true},
+				"password":    {Type: cty.String, Optional: true, Sensitive: true},
+				"private_key": {Type: cty.String, Optional: true, Sensitive: true},
+				"host_key":    {Type: cty.String, Optional: true},
+				"timeout":     {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+
+				"command":            {Type: cty.String, Required: true},
+				"expected_exit_code": {Type: cty.Number, Optional: true},
+				"stdout_pattern":     {Type: cty.String, Optional: true, ValidateFn: validateRegexp},
+				"stderr_pattern":     {Type: cty.String, Optional: true, ValidateFn: validateRegexp},
+
+				"exit_code": {Type: cty.Number, Computed: true},
+				"stdout":    {Type: cty.String, Computed: true},
+				"stderr":    {Type: cty.String, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *sshDRT) (*sshDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			connectTimeout := client.timeout(obj.Timeout, 1*time.Minute)
+			config := &ssh.ClientConfig{
+				User:    obj.User,
+				Timeout: connectTimeout,
+			}
+
+			if obj.PrivateKey != nil {
+				signer, err := ssh.ParsePrivateKey([]byte(*obj.PrivateKey))
+				if err != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Invalid private key",
+						Detail:   fmt.Sprintf("Failed to parse the SSH private key: %s.", err),
+						Path:     cty.Path(nil).GetAttr("private_key"),
+					})
+					return obj, diags
+				}
+				config.Auth = append(config.Auth, ssh.PublicKeys(signer))
+			}
+			if obj.Password != nil {
+				config.Auth = append(config.Auth, ssh.Password(*obj.Password))
+			}
+			if len(config.Auth) == 0 {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid SSH configuration",
+					Detail:   "At least one of the \"password\" and \"private_key\" arguments must be set, to specify how to authenticate with the SSH server.",
+				})
+				return obj, diags
+			}
+
+			if obj.HostKey != nil {
+				hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(*obj.HostKey))
+				if err != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Invalid host key",
+						Detail:   fmt.Sprintf("Failed to parse the SSH host key, which must use the same format as an entry in an OpenSSH authorized_keys file: %s.", err),
+						Path:     cty.Path(nil).GetAttr("host_key"),
+					})
+					return obj, diags
+				}
+				config.HostKeyCallback = ssh.FixedHostKey(hostKey)
+			} else {
+				// Test environments are often ephemeral VMs whose host keys
+				// can't be known in advance, so verification is opt-in.
+				config.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+			}
+
+			port := 22
+			if obj.Port != nil {
+				port = *obj.Port
+			}
+			addr := net.JoinHostPort(obj.Host, strconv.Itoa(port))
+
+			conn, err := sshDial(ctx, addr, config)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Failed to connect",
+					Detail:   fmt.Sprintf("Failed to establish an SSH connection to %s: %s.", addr, err),
+				})
+				return obj, diags
+			}
+			defer conn.Close()
+
+			session, err := conn.NewSession()
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Failed to start SSH session",
+					Detail:   fmt.Sprintf("Failed to start a session on %s: %s.", addr, err),
+				})
+				return obj, diags
+			}
+			defer session.Close()
+
+			var outBuf, errBuf bytes.Buffer
+			session.Stdout = &outBuf
+			session.Stderr = &errBuf
+
+			// The session doesn't watch ctx itself, so we close it and the
+			// connection if ctx is cancelled before the command exits, which
+			// makes Run return.
+			runErr := make(chan error, 1)
+			go func() {
+				runErr <- session.Run(obj.Command)
+			}()
+			select {
+			case err = <-runErr:
+			case <-ctx.Done():
+				session.Close()
+				conn.Close()
+				<-runErr
+				if ctx.Err() == context.DeadlineExceeded {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Data source timed out",
+						Detail:   fmt.Sprintf("The remote command on %s did not exit within the default_timeout of %s set in the provider configuration, so the SSH session was closed.", addr, client.defaultTimeout),
+						Path:     cty.Path(nil).GetAttr("command"),
+					})
+				} else {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Remote command cancelled",
+						Detail:   fmt.Sprintf("The SSH session on %s was closed before the remote command exited: %s.", addr, ctx.Err()),
+						Path:     cty.Path(nil).GetAttr("command"),
+					})
+				}
+				return obj, diags
+			}
+
+			exitCode := 0
+			if err != nil {
+				exitErr, ok := err.(*ssh.ExitError)
+				if !ok {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Remote command failed",
+						Detail:   fmt.Sprintf("Error running command on %s: %s.", addr, err),
+						Path:     cty.Path(nil).GetAttr("command"),
+					})
+					return obj, diags
+				}
+				exitCode = exitErr.ExitStatus()
+			}
+
+			stdout := outBuf.String()
+			stderr := errBuf.String()
+			obj.ExitCode = &exitCode
+			obj.Stdout = &stdout
+			obj.Stderr = &stderr
+
+			wantExitCode := 0
+			if obj.ExpectedExitCode != nil {
+				wantExitCode = *obj.ExpectedExitCode
+			}
+			if exitCode != wantExitCode {
+				stderrForOutput := strings.Replace(strings.TrimSpace(stderr), "\n", "\n  ", -1)
+				if stderrForOutput != "" {
+					stderrForOutput = "\n\nThe command produced the following error messages:\n  " + stderrForOutput
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   fmt.Sprintf("Assertion failed: remote command has expected exit code.\n  Want: %d\n  Got:  %d%s", wantExitCode, exitCode, stderrForOutput),
+					Path:     cty.Path(nil).GetAttr("expected_exit_code"),
+				})
+			}
+
+			diags = diags.Append(sshCheckPattern("stdout", obj.StdoutPattern, stdout))
+			diags = diags.Append(sshCheckPattern("stderr", obj.StderrPattern, stderr))
+
+			return obj, diags
+		},
+	})
+}
+
+// sshDial is like ssh.Dial, but it also gives up if the given context is
+// cancelled while connecting.
+func sshDial(ctx context.Context, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	d := net.Dialer{Timeout: config.Timeout}
+	netConn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// ssh.NewClientConn doesn't honor config.Timeout or ctx for the
+	// handshake, so we bound it with a deadline on the underlying
+	// connection, cleared again once the handshake is done.
+	deadline := time.Now().Add(config.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	netConn.SetDeadline(deadline)
+	c, chans, reqs, err := ssh.NewClientConn(netConn, addr, config)
+	if err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	netConn.SetDeadline(time.Time{})
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+// sshCheckPattern returns an error diagnostic if the given output does not
+// match the given pattern, which must already have been validated using
+// validateRegexp. A nil pattern always matches.
+func sshCheckPattern(stream string, pattern *string, output string) tfsdk.Diagnostics {
+	var diags tfsdk.Diagnostics
+	if pattern == nil {
+		return diags
+	}
+	re := regexp.MustCompile(*pattern)
+	if !re.MatchString(output) {
+		diags = diags.Append(tfsdk.Diagnostic{
+			Severity: tfsdk.Error,
+			Summary:  "Test failure",
+			Detail:   fmt.Sprintf("Assertion failed: remote command %s matches pattern.\n  Want: %s\n  Got:  %s", stream, formatValue(cty.StringVal(*pattern), 2), formatValue(cty.StringVal(output), 2)),
+			Path:     cty.Path(nil).GetAttr(stream + "_pattern"),
+		})
+	}
+	return diags
+}
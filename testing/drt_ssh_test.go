@@ -0,0 +1,259 @@
+package testing
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestDRTSSH(t *testing.T) {
+	addr, hostKey := startTestSSHServer(t, "tester", "secret")
+	host, port, _ := net.SplitHostPort(addr)
+
+	t.Run("command pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_ssh" "test" {
+  host     = %q
+  port     = %s
+  user     = "tester"
+  password = "secret"
+  host_key = %q
+
+  command        = "echo hello; echo oops >&2"
+  stdout_pattern = "^hello"
+  stderr_pattern = "oops"
+}
+
+data "testing_assertions" "test" {
+  equal "stdout" {
+	got  = data.testing_ssh.test.stdout
+	want = "hello\n"
+  }
+  equal "exit_code" {
+	got  = data.testing_ssh.test.exit_code
+	want = 0
+  }
+}
+`, host, port, hostKey))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("expected exit code", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_ssh" "test" {
+  host     = %q
+  port     = %s
+  user     = "tester"
+  password = "secret"
+
+  command            = "exit 3"
+  expected_exit_code = 3
+}
+`, host, port))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("exit code fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_ssh" "test" {
+  host     = %q
+  port     = %s
+  user     = "tester"
+  password = "secret"
+
+  command = "false"
+}
+`, host, port))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("pattern fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_ssh" "test" {
+  host     = %q
+  port     = %s
+  user     = "tester"
+  password = "secret"
+
+  command        = "echo hello"
+  stdout_pattern = "goodbye"
+}
+`, host, port))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("command never exits", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+provider "testing" {
+  default_timeout = "1s"
+}
+
+data "testing_ssh" "test" {
+  host     = %q
+  port     = %s
+  user     = "tester"
+  password = "secret"
+
+  command = "exec sleep 120"
+}
+`, host, port))
+
+		wd.RequireInit(t)
+		start := time.Now()
+		err := wd.Apply()
+		if err == nil {
+			t.Fatal("succeeded; want error")
+		}
+		if want := "Data source timed out"; strings.Count(err.Error(), want) != 1 {
+			t.Errorf("error does not contain %q exactly once\n%s", want, err)
+		}
+		if d := time.Since(start); d > time.Minute {
+			t.Errorf("apply took %s; should have stopped after the default timeout", d)
+		}
+	})
+	t.Run("wrong password", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_ssh" "test" {
+  host     = %q
+  port     = %s
+  user     = "tester"
+  password = "wrong"
+
+  command = "true"
+}
+`, host, port))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
+
+// startTestSSHServer starts a minimal SSH server that accepts the given
+// username and password and runs "exec" requests using the local shell. It
+// returns the address the server is listening on and its host key in
+// authorized_keys format.
+func startTestSSHServer(t *testing.T, user, password string) (string, string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == user && string(pass) == password {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("incorrect password for %q", c.User())
+		},
+	}
+	config.AddHostKey(signer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSSHConn(conn, config)
+		}
+	}()
+
+	hostKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+	return ln.Addr().String(), hostKey
+}
+
+func serveTestSSHConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newCh := range chans {
+		if newCh.ChannelType() != "session" {
+			newCh.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		ch, reqs, err := newCh.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer ch.Close()
+			for req := range reqs {
+				if req.Type != "exec" {
+					req.Reply(false, nil)
+					continue
+				}
+				var payload struct{ Command string }
+				if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+					req.Reply(false, nil)
+					continue
+				}
+				req.Reply(true, nil)
+
+				cmd := exec.Command("sh", "-c", payload.Command)
+				cmd.Stdout = ch
+				cmd.Stderr = ch.Stderr()
+				status := uint32(0)
+				if err := cmd.Run(); err != nil {
+					status = 1
+					if exitErr, ok := err.(*exec.ExitError); ok {
+						status = uint32(exitErr.ExitCode())
+					}
+				}
+				var statusPayload [4]byte
+				binary.BigEndian.PutUint32(statusPayload[:], status)
+				ch.SendRequest("exit-status", false, statusPayload[:])
+				return
+			}
+		}()
+	}
+}
@@ -4,8 +4,6 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
 
 	"github.com/apparentlymart/go-test-anything/tap"
@@ -20,21 +18,13 @@ type tapDRT struct {
 }
 
 func tapDataResourceType() tfsdk.DataResourceType {
-	return tfsdk.NewDataResourceType(&tfsdk.ResourceTypeDef{
+	return newDataResourceType("testing_tap", &tfsdk.ResourceTypeDef{
 		ConfigSchema: &tfschema.BlockType{
 			Attributes: map[string]*tfschema.Attribute{
 				"program": {
-					Type:     cty.List(cty.String),
-					Required: true,
-					ValidateFn: func(v []string) tfsdk.Diagnostics {
-						var diags tfsdk.Diagnostics
-						if len(v) < 1 {
-							diags = diags.Append(tfsdk.ValidationError(
-								cty.Path(nil).GetAttr("program").NewErrorf("must have at least one element to specify the executable to run"),
-							))
-						}
-						return diags
-					},
+					Type:       cty.List(cty.String),
+					Required:   true,
+					ValidateFn: validateProgram,
 				},
 				"environment": {
 					Type:     cty.Map(cty.String),
@@ -46,18 +36,22 @@ func tapDataResourceType() tfsdk.DataResourceType {
 		ReadFn: func(ctx context.Context, client *Client, obj *tapDRT) (*tapDRT, tfsdk.Diagnostics) {
 			var diags tfsdk.Diagnostics
 
-			cmd := exec.CommandContext(ctx, obj.Program[0], obj.Program[1:]...)
+			cmd := programCommand(ctx, client, obj.Program, obj.Environment)
 			var outBuf, errBuf bytes.Buffer
 			cmd.Stdout = &outBuf
 			cmd.Stderr = &errBuf
-			for _, e := range os.Environ() {
-				cmd.Env = append(cmd.Env, e)
-			}
-			for k, v := range obj.Environment {
-				cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
-			}
 
-			err := cmd.Run()
+			release, err := client.acquireProgram(ctx)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test program cancelled",
+					Detail:   fmt.Sprintf("The test program was not started: %s.", err),
+				})
+				return obj, diags
+			}
+			err = cmd.Run()
+			release()
 
 			stderrForOutput := strings.Replace(errBuf.String(), "\n", "\n  ", -1)
 			if stderrForOutput != "" {
@@ -91,6 +85,9 @@ func tapDataResourceType() tfsdk.DataResourceType {
 			}
 
 			for _, test := range report.Tests {
+				if client.failedFast(diags) {
+					break
+				}
 				testName := test.Name
 				if testName == "" {
 					testName = fmt.Sprintf("anonymous test #%d", test.Num)
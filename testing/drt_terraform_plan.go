@@ -0,0 +1,229 @@
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type terraformPlanDRT struct {
+	Dir         string            `cty:"dir"`
+	Program     []string          `cty:"program"`
+	Environment map[string]string `cty:"environment"`
+	Variables   map[string]string `cty:"variables"`
+	Init        *bool             `cty:"init"`
+	Subject     *string           `cty:"subject"`
+
+	ExpectCreate  *int              `cty:"expect_create"`
+	ExpectUpdate  *int              `cty:"expect_update"`
+	ExpectReplace *int              `cty:"expect_replace"`
+	ExpectDelete  *int              `cty:"expect_delete"`
+	ExpectActions map[string]string `cty:"expect_actions"`
+
+	Actions      map[string]string `cty:"actions"`
+	CreateCount  *int              `cty:"create_count"`
+	UpdateCount  *int              `cty:"update_count"`
+	ReplaceCount *int              `cty:"replace_count"`
+	DeleteCount  *int              `cty:"delete_count"`
+}
+
+// terraformPlanJSON is the subset of the output of "terraform show -json"
+// for a saved plan that testing_terraform_plan uses.
+type terraformPlanJSON struct {
+	ResourceChanges []struct {
+		Address string `json:"address"`
+		Mode    string `json:"mode"`
+		Change  struct {
+			Actions []string `json:"actions"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+}
+
+func terraformPlanDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_terraform_plan", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"dir": {Type: cty.String, Required: true},
+				"program": {
+					Type:       cty.List(cty.String),
+					Optional:   true,
+					ValidateFn: validateProgram,
+				},
+				"environment": {Type: cty.Map(cty.String), Optional: true},
+				"variables":   {Type: cty.Map(cty.String), Optional: true},
+				"init":        {Type: cty.Bool, Optional: true},
+				"subject":     {Type: cty.String, Optional: true},
+
+				"expect_create":  {Type: cty.Number, Optional: true},
+				"expect_update":  {Type: cty.Number, Optional: true},
+				"expect_replace": {Type: cty.Number, Optional: true},
+				"expect_delete":  {Type: cty.Number, Optional: true},
+				"expect_actions": {Type: cty.Map(cty.String), Optional: true},
+
+				"actions":       {Type: cty.Map(cty.String), Computed: true},
+				"create_count":  {Type: cty.Number, Computed: true},
+				"update_count":  {Type: cty.Number, Computed: true},
+				"replace_count": {Type: cty.Number, Computed: true},
+				"delete_count":  {Type: cty.Number, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *terraformPlanDRT) (*terraformPlanDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			tmpDir, err := ioutil.TempDir("", "terraform-provider-testing-plan")
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Failed to create plan",
+					Detail:   fmt.Sprintf("Error creating temporary directory: %s.", err),
+				})
+				return obj, diags
+			}
+			defer os.RemoveAll(tmpDir)
+			planFile := filepath.Join(tmpDir, "tfplan")
+
+			// When we initialize the configuration ourselves, we do so in a
+			// data directory of our own, so that the .terraform directory
+			// in dir is left unchanged, unless the configuration
+			// explicitly overrides this.
+			runInit := obj.Init == nil || *obj.Init
+			env := map[string]string{}
+			if runInit {
+				env["TF_IN_AUTOMATION"] = "1"
+				env["TF_DATA_DIR"] = filepath.Join(tmpDir, ".terraform")
+			}
+			for k, v := range obj.Environment {
+				env[k] = v
+			}
+
+			run := func(args ...string) ([]byte, error) {
+				return runTerraform(ctx, client, obj.Program, env, obj.Dir, args...)
+			}
+			fail := func(summary, step string, err error) {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  summary,
+					Detail:   fmt.Sprintf("Error running \"terraform %s\" in %s: %s.", step, obj.Dir, err),
+					Path:     cty.Path(nil).GetAttr("dir"),
+				})
+			}
+
+			if runInit {
+				if _, err := run("init", "-input=false"); err != nil {
+					fail("Failed to initialize configuration", "init", err)
+					return obj, diags
+				}
+			}
+
+			planArgs := []string{"plan", "-input=false", "-lock=false", "-out=" + planFile}
+			varNames := make([]string, 0, len(obj.Variables))
+			for name := range obj.Variables {
+				varNames = append(varNames, name)
+			}
+			sort.Strings(varNames)
+			for _, name := range varNames {
+				planArgs = append(planArgs, "-var", fmt.Sprintf("%s=%s", name, obj.Variables[name]))
+			}
+			if _, err := run(planArgs...); err != nil {
+				fail("Failed to create plan", "plan", err)
+				return obj, diags
+			}
+
+			out, err := run("show", "-json", planFile)
+			if err != nil {
+				fail("Failed to read plan", "show -json", err)
+				return obj, diags
+			}
+			var plan terraformPlanJSON
+			if err := json.Unmarshal(out, &plan); err != nil {
+				fail("Failed to read plan", "show -json", fmt.Errorf("invalid JSON output: %s", err))
+				return obj, diags
+			}
+
+			counts := map[string]int{}
+			obj.Actions = make(map[string]string)
+			for _, rc := range plan.ResourceChanges {
+				if rc.Mode == "data" {
+					// Data resources are read, not changed, so we don't
+					// consider them part of the plan's changes.
+					continue
+				}
+				action := terraformPlanAction(rc.Change.Actions)
+				obj.Actions[rc.Address] = action
+				counts[action]++
+			}
+			create, update, replace, del := counts["create"], counts["update"], counts["replace"], counts["delete"]
+			obj.CreateCount, obj.UpdateCount, obj.ReplaceCount, obj.DeleteCount = &create, &update, &replace, &del
+
+			subject := "plan"
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+			checkCount := func(what, attr string, want *int, got int) {
+				if want == nil || *want == got || client.failedFast(diags) {
+					return
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   namedAssertionFailureMsg(fmt.Sprintf("%s %s the expected number of resource instances", subject, what), "count", cty.NumberIntVal(int64(*want)), cty.NumberIntVal(int64(got))),
+					Path:     cty.Path(nil).GetAttr(attr),
+				})
+			}
+			checkCount("creates", "expect_create", obj.ExpectCreate, create)
+			checkCount("updates", "expect_update", obj.ExpectUpdate, update)
+			checkCount("replaces", "expect_replace", obj.ExpectReplace, replace)
+			checkCount("deletes", "expect_delete", obj.ExpectDelete, del)
+
+			addrs := make([]string, 0, len(obj.ExpectActions))
+			for addr := range obj.ExpectActions {
+				addrs = append(addrs, addr)
+			}
+			sort.Strings(addrs)
+			for _, addr := range addrs {
+				if client.failedFast(diags) {
+					break
+				}
+				want := obj.ExpectActions[addr]
+				got, planned := obj.Actions[addr]
+				if !planned {
+					got = "no-op"
+				}
+				if got == want {
+					continue
+				}
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   namedAssertionFailureMsg(fmt.Sprintf("%s has the expected action for %s", subject, addr), "action", cty.StringVal(want), cty.StringVal(got)),
+					Path:     cty.Path(nil).GetAttr("expect_actions").Index(cty.StringVal(addr)),
+				})
+			}
+
+			return obj, diags
+		},
+	})
+}
+
+// terraformPlanAction summarizes the list of actions Terraform reports for a
+// resource instance change as a single word.
+func terraformPlanAction(actions []string) string {
+	switch strings.Join(actions, ",") {
+	case "create,delete", "delete,create":
+		return "replace"
+	case "":
+		return "no-op"
+	default:
+		return strings.Join(actions, ",")
+	}
+}
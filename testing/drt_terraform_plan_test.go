@@ -0,0 +1,140 @@
+package testing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testTerraformPlanJSON = `{
+  "format_version": "0.1",
+  "terraform_version": "0.12.0",
+  "resource_changes": [
+    {"address": "aws_instance.web[0]", "mode": "managed", "change": {"actions": ["create"]}},
+    {"address": "aws_instance.web[1]", "mode": "managed", "change": {"actions": ["create"]}},
+    {"address": "aws_security_group.web", "mode": "managed", "change": {"actions": ["update"]}},
+    {"address": "aws_eip.web", "mode": "managed", "change": {"actions": ["delete", "create"]}},
+    {"address": "aws_vpc.main", "mode": "managed", "change": {"actions": ["no-op"]}},
+    {"address": "data.aws_ami.ubuntu", "mode": "data", "change": {"actions": ["read"]}}
+  ]
+}
+`
+
+// testTerraformPlanScript stands in for the Terraform CLI, so that these
+// tests don't need a configuration with real providers.
+const testTerraformPlanScript = `
+case "$1" in
+  init) echo "$TF_DATA_DIR" > init-data-dir.txt ;;
+  plan) echo "$@" > plan-args.txt ;;
+  show) cat plan.json ;;
+  *) echo "unexpected command $1" >&2; exit 1 ;;
+esac
+`
+
+func TestDRTTerraformPlan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-testing-terraform-plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "plan.json"), []byte(testTerraformPlanJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, "terraform.sh")
+	if err := ioutil.WriteFile(script, []byte(testTerraformPlanScript), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_terraform_plan" "test" {
+  dir     = %q
+  program = ["sh", %q]
+  variables = {
+    region = "us-east-1"
+  }
+
+  expect_create  = 2
+  expect_update  = 1
+  expect_replace = 1
+  expect_delete  = 0
+  expect_actions = {
+    "aws_eip.web"  = "replace"
+    "aws_vpc.main" = "no-op"
+    "aws_s3.other" = "no-op"
+  }
+}
+
+data "testing_assertions" "test" {
+  equal "web_action" {
+    got  = data.testing_terraform_plan.test.actions["aws_instance.web[0]"]
+    want = "create"
+  }
+}
+`, dir, script))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+
+		args, err := ioutil.ReadFile(filepath.Join(dir, "plan-args.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(args), "-var region=us-east-1") {
+			t.Errorf("plan was not given the variables\nargs: %s", args)
+		}
+
+		dataDir, err := ioutil.ReadFile(filepath.Join(dir, "init-data-dir.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.TrimSpace(string(dataDir)); got == "" || strings.HasPrefix(got, dir) {
+			t.Errorf("init used data directory %q; want a temporary directory outside %s", got, dir)
+		}
+	})
+	t.Run("fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_terraform_plan" "test" {
+  dir     = %q
+  program = ["sh", %q]
+
+  expect_delete = 0
+  expect_actions = {
+    "aws_security_group.web" = "no-op"
+  }
+}
+`, dir, script))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("command fails", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_terraform_plan" "test" {
+  dir     = %q
+  program = ["sh", "-c", "exit 1", "sh"]
+}
+`, dir))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
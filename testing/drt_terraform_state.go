@@ -0,0 +1,108 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type terraformStateDRT struct {
+	Path        *string           `cty:"path"`
+	Dir         *string           `cty:"dir"`
+	Program     []string          `cty:"program"`
+	Environment map[string]string `cty:"environment"`
+
+	Outputs          cty.Value `cty:"outputs"`
+	Resources        cty.Value `cty:"resources"`
+	ResourceAddrs    []string  `cty:"resource_addresses"`
+	TerraformVersion *string   `cty:"terraform_version"`
+	Serial           *int64    `cty:"serial"`
+	Lineage          *string   `cty:"lineage"`
+}
+
+func terraformStateDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_terraform_state", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"path": {Type: cty.String, Optional: true},
+				"dir":  {Type: cty.String, Optional: true},
+				"program": {
+					Type:       cty.List(cty.String),
+					Optional:   true,
+					ValidateFn: validateProgram,
+				},
+				"environment": {Type: cty.Map(cty.String), Optional: true},
+
+				"outputs":            {Type: cty.DynamicPseudoType, Computed: true},
+				"resources":          {Type: cty.DynamicPseudoType, Computed: true},
+				"resource_addresses": {Type: cty.List(cty.String), Computed: true},
+				"terraform_version":  {Type: cty.String, Computed: true},
+				"serial":             {Type: cty.Number, Computed: true},
+				"lineage":            {Type: cty.String, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *terraformStateDRT) (*terraformStateDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			if (obj.Path == nil) == (obj.Dir == nil) {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid state source",
+					Detail:   "Exactly one of the \"path\" and \"dir\" arguments must be set, to select where to read the state from.",
+				})
+				return obj, diags
+			}
+
+			var src []byte
+			var err error
+			var attr, source string
+			if obj.Path != nil {
+				attr, source = "path", *obj.Path
+				src, err = ioutil.ReadFile(*obj.Path)
+			} else {
+				attr, source = "dir", *obj.Dir
+				src, err = runTerraform(ctx, client, obj.Program, obj.Environment, *obj.Dir, "state", "pull")
+				if err == nil && isTFStateMissing(src) {
+					err = fmt.Errorf("there is no state for the configuration in this directory")
+				}
+			}
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Failed to read state",
+					Detail:   fmt.Sprintf("Error reading Terraform state from %s: %s.", source, err),
+					Path:     cty.Path(nil).GetAttr(attr),
+				})
+				return obj, diags
+			}
+
+			state, err := parseTFState(src)
+			if err == nil {
+				obj.Outputs, err = state.OutputValues()
+			}
+			if err == nil {
+				obj.Resources, err = state.ResourceInstances()
+			}
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid Terraform state",
+					Detail:   fmt.Sprintf("The state read from %s is invalid: %s.", source, err),
+					Path:     cty.Path(nil).GetAttr(attr),
+				})
+				return obj, diags
+			}
+			obj.ResourceAddrs = tfStateAddrs(obj.Resources)
+			obj.TerraformVersion = &state.TerraformVersion
+			obj.Serial = &state.Serial
+			obj.Lineage = &state.Lineage
+
+			return obj, diags
+		},
+	})
+}
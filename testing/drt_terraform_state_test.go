@@ -0,0 +1,138 @@
+package testing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testTerraformState = `{
+  "version": 4,
+  "terraform_version": "0.12.0",
+  "serial": 3,
+  "lineage": "5b2f3c9e-7a1d-4b8e-9f0a-1c2d3e4f5a6b",
+  "outputs": {
+    "ids": {
+      "value": ["a", "b"],
+      "type": ["list", "string"]
+    },
+    "name": {
+      "value": "example",
+      "type": "string"
+    }
+  },
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "web",
+      "each": "list",
+      "provider": "provider.aws",
+      "instances": [
+        {"index_key": 0, "schema_version": 1, "attributes": {"id": "i-1", "tags": {"Name": "web-0"}}},
+        {"index_key": 1, "schema_version": 1, "attributes": {"id": "i-2", "tags": {"Name": "web-1"}}}
+      ]
+    },
+    {
+      "module": "module.network",
+      "mode": "data",
+      "type": "aws_vpc",
+      "name": "default",
+      "provider": "provider.aws",
+      "instances": [
+        {"schema_version": 0, "attributes": {"id": "vpc-1", "cidr_block": "10.0.0.0/16"}}
+      ]
+    }
+  ]
+}
+`
+
+func TestDRTTerraformState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-testing-terraform-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	statePath := filepath.Join(dir, "terraform.tfstate")
+	if err := ioutil.WriteFile(statePath, []byte(testTerraformState), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("path", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_terraform_state" "test" {
+  path = %q
+}
+
+data "testing_assertions" "test" {
+  equal "ids" {
+    got  = data.testing_terraform_state.test.outputs.ids
+    want = tolist(["a", "b"])
+  }
+  equal "instance_name" {
+    got  = data.testing_terraform_state.test.resources["aws_instance.web[1]"].tags.Name
+    want = "web-1"
+  }
+  equal "addresses" {
+    got = data.testing_terraform_state.test.resource_addresses
+    want = tolist([
+      "aws_instance.web[0]",
+      "aws_instance.web[1]",
+      "module.network.data.aws_vpc.default",
+    ])
+  }
+  equal "serial" {
+    got  = data.testing_terraform_state.test.serial
+    want = 3
+  }
+}
+`, statePath))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("dir", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		// We use a stand-in for "terraform state pull" so that this test
+		// doesn't need a separately-initialized configuration.
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_terraform_state" "test" {
+  dir     = %q
+  program = ["sh", "-c", "cat terraform.tfstate", "sh"]
+}
+
+data "testing_assertions" "test" {
+  equal "name" {
+    got  = data.testing_terraform_state.test.outputs.name
+    want = "example"
+  }
+}
+`, dir))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("missing", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_terraform_state" "test" {
+  path = %q
+}
+`, filepath.Join(dir, "nonexistent.tfstate")))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type timeDRT struct {
+	Timestamp   string  `cty:"timestamp"`
+	MaxAge      *string `cty:"max_age"`
+	MinAge      *string `cty:"min_age"`
+	AllowedSkew *string `cty:"allowed_skew"`
+	Subject     *string `cty:"subject"`
+
+	RFC3339 *string  `cty:"rfc3339"`
+	Unix    *float64 `cty:"unix"`
+	Age     *float64 `cty:"age"`
+}
+
+func timeDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_time", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"timestamp":    {Type: cty.String, Required: true},
+				"max_age":      {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"min_age":      {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"allowed_skew": {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"subject":      {Type: cty.String, Optional: true},
+
+				"rfc3339": {Type: cty.String, Computed: true},
+				"unix":    {Type: cty.Number, Computed: true},
+				"age":     {Type: cty.Number, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *timeDRT) (*timeDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			ts, err := parseTimestamp(obj.Timestamp)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid timestamp",
+					Detail:   fmt.Sprintf("Cannot parse %q as a timestamp: %s.", obj.Timestamp, err),
+					Path:     cty.Path(nil).GetAttr("timestamp"),
+				})
+				return obj, diags
+			}
+
+			now := time.Now()
+			age := now.Sub(ts)
+			rfc3339 := ts.UTC().Format(time.RFC3339Nano)
+			unix := float64(ts.UnixNano()) / float64(time.Second)
+			ageSecs := age.Seconds()
+			obj.RFC3339 = &rfc3339
+			obj.Unix = &unix
+			obj.Age = &ageSecs
+
+			if obj.MaxAge == nil && obj.MinAge == nil {
+				return obj, diags
+			}
+
+			what := "timestamp"
+			if obj.Subject != nil {
+				what = *obj.Subject
+			}
+			fail := func(statement, attr string) {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   fmt.Sprintf("Assertion failed: %s %s.\n\nThe timestamp is %s (%s), and the current time is %s.", what, statement, rfc3339, describeAge(age), now.UTC().Format(time.RFC3339)),
+					Path:     cty.Path(nil).GetAttr(attr),
+				})
+			}
+
+			skew := durationOrDefault(obj.AllowedSkew, time.Minute)
+			if age < -skew {
+				fail("is not in the future", "timestamp")
+				return obj, diags
+			}
+			if obj.MaxAge != nil {
+				maxAge := durationOrDefault(obj.MaxAge, 0)
+				if age > maxAge {
+					fail(fmt.Sprintf("is no more than %s old", maxAge), "max_age")
+				}
+			}
+			if obj.MinAge != nil {
+				minAge := durationOrDefault(obj.MinAge, 0)
+				if age < minAge {
+					fail(fmt.Sprintf("is at least %s old", minAge), "min_age")
+				}
+			}
+
+			return obj, diags
+		},
+	})
+}
+
+// parseTimestamp parses either an RFC3339 timestamp or a number of seconds
+// since the Unix epoch, which may have a fractional part.
+func parseTimestamp(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		if math.IsNaN(secs) || math.IsInf(secs, 0) {
+			return time.Time{}, fmt.Errorf("not a finite number of seconds")
+		}
+		whole, frac := math.Modf(secs)
+		return time.Unix(int64(whole), int64(frac*float64(time.Second))), nil
+	}
+	ts, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be either an RFC3339 timestamp or a number of seconds since the Unix epoch")
+	}
+	return ts, nil
+}
+
+// describeAge returns a human-readable description of how long ago, or how
+// far in the future, a time with the given age is.
+func describeAge(age time.Duration) string {
+	if age < 0 {
+		return fmt.Sprintf("%s in the future", (-age).Round(time.Second))
+	}
+	return fmt.Sprintf("%s ago", age.Round(time.Second))
+}
@@ -0,0 +1,108 @@
+package testing
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDRTTime(t *testing.T) {
+	t.Run("fresh pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_time" "test" {
+  timestamp = timeadd(timestamp(), "-5m")
+  max_age   = "10m"
+  min_age   = "1m"
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("unix", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_time" "test" {
+  timestamp = "1555555555.5"
+}
+
+data "testing_assertions" "test" {
+  equal "rfc3339" {
+    got  = data.testing_time.test.rfc3339
+    want = "2019-04-18T02:45:55.5Z"
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("stale fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_time" "test" {
+  timestamp = "%d"
+  max_age   = "10m"
+}
+`, time.Now().Add(-time.Hour).Unix()))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("future fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_time" "test" {
+  timestamp = timeadd(timestamp(), "1h")
+  max_age   = "10m"
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := map[string]time.Time{
+		"2019-04-18T02:45:55Z":      time.Date(2019, 4, 18, 2, 45, 55, 0, time.UTC),
+		"2019-04-18T04:45:55+02:00": time.Date(2019, 4, 18, 2, 45, 55, 0, time.UTC),
+		"1555555555":                time.Date(2019, 4, 18, 2, 45, 55, 0, time.UTC),
+		" 1555555555.25\n":          time.Date(2019, 4, 18, 2, 45, 55, 250000000, time.UTC),
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			got, err := parseTimestamp(input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !got.Equal(want) {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "yesterday", "2019-04-18", "NaN"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := parseTimestamp(input)
+			if err == nil {
+				t.Error("succeeded; want error")
+			}
+		})
+	}
+}
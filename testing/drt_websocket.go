@@ -0,0 +1,210 @@
+package testing
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/net/websocket"
+)
+
+type websocketDRT struct {
+	URL       string            `cty:"url"`
+	Origin    *string           `cty:"origin"`
+	Headers   map[string]string `cty:"headers"`
+	Timeout   *string           `cty:"timeout"`
+	Subject   *string           `cty:"subject"`
+	Statement *string           `cty:"statement"`
+
+	Send           *string `cty:"send"`
+	ExpectMessage  *string `cty:"expect_message"`
+	MessagePattern *string `cty:"message_pattern"`
+
+	Message *string `cty:"message"`
+}
+
+func websocketDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_websocket", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"url":       {Type: cty.String, Required: true},
+				"origin":    {Type: cty.String, Optional: true},
+				"headers":   {Type: cty.Map(cty.String), Optional: true},
+				"timeout":   {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"subject":   {Type: cty.String, Optional: true},
+				"statement": {Type: cty.String, Optional: true},
+
+				"send":            {Type: cty.String, Optional: true},
+				"expect_message":  {Type: cty.String, Optional: true},
+				"message_pattern": {Type: cty.String, Optional: true, ValidateFn: validateRegexp},
+
+				"message": {Type: cty.String, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *websocketDRT) (*websocketDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			timeout := client.timeout(obj.Timeout, 10*time.Second)
+			deadline := time.Now().Add(timeout)
+			if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+				deadline = d
+			}
+
+			u, err := url.Parse(obj.URL)
+			if err != nil || (u.Scheme != "ws" && u.Scheme != "wss") {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid websocket URL",
+					Detail:   "The \"url\" argument must be an absolute URL using either the \"ws\" or \"wss\" scheme.",
+					Path:     cty.Path(nil).GetAttr("url"),
+				})
+				return obj, diags
+			}
+
+			origin := websocketDefaultOrigin(u)
+			if obj.Origin != nil {
+				origin = *obj.Origin
+			}
+			config, err := websocket.NewConfig(obj.URL, origin)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid websocket origin",
+					Detail:   fmt.Sprintf("Cannot use the given origin: %s.", err),
+					Path:     cty.Path(nil).GetAttr("origin"),
+				})
+				return obj, diags
+			}
+			for name, value := range obj.Headers {
+				config.Header.Set(name, value)
+			}
+
+			ws, err := websocketDial(config, deadline)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Websocket connection failed",
+					Detail:   fmt.Sprintf("Error connecting to %s: %s.", obj.URL, err),
+					Path:     cty.Path(nil).GetAttr("url"),
+				})
+				return obj, diags
+			}
+			defer ws.Close()
+
+			if obj.Send != nil {
+				if err := websocket.Message.Send(ws, *obj.Send); err != nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Websocket send failed",
+						Detail:   fmt.Sprintf("Error sending message to %s: %s.", obj.URL, err),
+						Path:     cty.Path(nil).GetAttr("send"),
+					})
+					return obj, diags
+				}
+			}
+
+			if obj.Send == nil && obj.ExpectMessage == nil && obj.MessagePattern == nil {
+				// Nothing to wait for, so a successful handshake is enough.
+				return obj, diags
+			}
+
+			var msg string
+			if err := websocket.Message.Receive(ws, &msg); err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Websocket receive failed",
+					Detail:   fmt.Sprintf("Error waiting for a message from %s: %s.", obj.URL, err),
+					Path:     cty.Path(nil).GetAttr("url"),
+				})
+				return obj, diags
+			}
+			obj.Message = &msg
+
+			statement := "responds with the expected message"
+			if obj.Statement != nil {
+				statement = *obj.Statement
+			}
+			if obj.Subject != nil {
+				statement = fmt.Sprintf("%s %s", *obj.Subject, statement)
+			}
+			if obj.ExpectMessage != nil && msg != *obj.ExpectMessage {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Test failure",
+					Detail:   namedAssertionFailureMsg(statement, "message", cty.StringVal(*obj.ExpectMessage), cty.StringVal(msg)),
+					Path:     cty.Path(nil).GetAttr("expect_message"),
+				})
+			}
+			if obj.MessagePattern != nil {
+				// The pattern was already checked by validateRegexp.
+				re := regexp.MustCompile(*obj.MessagePattern)
+				if !re.MatchString(msg) {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   fmt.Sprintf("Assertion failed: %s.\n  Want message matching: %s\n  Got message:           %s", statement, formatValue(cty.StringVal(*obj.MessagePattern), 2), formatValue(cty.StringVal(msg), 2)),
+						Path:     cty.Path(nil).GetAttr("message_pattern"),
+					})
+				}
+			}
+
+			return obj, diags
+		},
+	}, ownTimeout)
+}
+
+// websocketDial opens a websocket connection using the given configuration,
+// failing if the connection and handshake are not complete by the given
+// deadline. The deadline also applies to any later reads and writes.
+func websocketDial(config *websocket.Config, deadline time.Time) (*websocket.Conn, error) {
+	host := config.Location.Host
+	if config.Location.Port() == "" {
+		if config.Location.Scheme == "wss" {
+			host = net.JoinHostPort(config.Location.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(config.Location.Hostname(), "80")
+		}
+	}
+
+	dialer := &net.Dialer{Deadline: deadline}
+	var conn net.Conn
+	var err error
+	if config.Location.Scheme == "wss" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, &tls.Config{
+			ServerName: config.Location.Hostname(),
+		})
+	} else {
+		conn, err = dialer.Dial("tcp", host)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	ws, err := websocket.NewClient(config, conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ws, nil
+}
+
+// websocketDefaultOrigin returns the origin that a browser would send when
+// connecting to the given websocket URL from a page on the same host.
+func websocketDefaultOrigin(u *url.URL) string {
+	scheme := "http"
+	if u.Scheme == "wss" {
+		scheme = "https"
+	}
+	return scheme + "://" + u.Host
+}
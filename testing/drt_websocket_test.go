@@ -0,0 +1,99 @@
+package testing
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestDRTWebsocket(t *testing.T) {
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		var msg string
+		if err := websocket.Message.Receive(ws, &msg); err != nil {
+			return
+		}
+		websocket.Message.Send(ws, strings.ToUpper(msg))
+	}))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	t.Run("connect", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_websocket" "test" {
+  url = %q
+}
+`, wsURL))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("message pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_websocket" "test" {
+  url  = %q
+  send = "hello"
+
+  expect_message  = "HELLO"
+  message_pattern = "^HEL+O$"
+}
+
+data "testing_assertions" "test" {
+  equal "message" {
+    got  = data.testing_websocket.test.message
+    want = "HELLO"
+  }
+}
+`, wsURL))
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("message fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_websocket" "test" {
+  url  = %q
+  send = "hello"
+
+  expect_message = "hello"
+}
+`, wsURL))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("timeout", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		// The server never sends a message unless we send one first.
+		wd.RequireSetConfig(t, fmt.Sprintf(`
+data "testing_websocket" "test" {
+  url     = %q
+  timeout = "500ms"
+
+  message_pattern = "."
+}
+`, wsURL))
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
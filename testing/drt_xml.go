@@ -0,0 +1,193 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/antchfx/xpath"
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+type xmlDRT struct {
+	Input   string  `cty:"input"`
+	Subject *string `cty:"subject"`
+
+	Queries cty.Value           `cty:"query"`
+	Values  map[string][]string `cty:"values"`
+}
+
+type xmlDRTQuery struct {
+	XPath     string  `cty:"xpath"`
+	Statement *string `cty:"statement"`
+
+	Want  *string `cty:"want"`
+	Count *int    `cty:"count"`
+}
+
+func xmlDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_xml", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"input":   {Type: cty.String, Required: true},
+				"subject": {Type: cty.String, Optional: true},
+
+				"values": {Type: cty.Map(cty.List(cty.String)), Computed: true},
+			},
+			NestedBlockTypes: map[string]*tfschema.NestedBlockType{
+				"query": {
+					Nesting: tfschema.NestingMap,
+					Content: tfschema.BlockType{
+						Attributes: map[string]*tfschema.Attribute{
+							"xpath": {
+								Type:     cty.String,
+								Required: true,
+								ValidateFn: func(v string) tfsdk.Diagnostics {
+									var diags tfsdk.Diagnostics
+									if _, err := xpath.Compile(v); err != nil {
+										diags = diags.Append(tfsdk.ValidationError(
+											cty.Path(nil).NewErrorf("must be a valid XPath expression: %s", err),
+										))
+									}
+									return diags
+								},
+							},
+							"statement": {Type: cty.String, Optional: true},
+
+							"want":  {Type: cty.String, Optional: true},
+							"count": {Type: cty.Number, Optional: true},
+						},
+					},
+				},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *xmlDRT) (*xmlDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			subject := ""
+			if obj.Subject != nil {
+				subject = *obj.Subject
+			}
+
+			root, err := parseXML(obj.Input)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid XML",
+					Detail:   fmt.Sprintf("The given input is not a valid XML document: %s.", err),
+					Path:     cty.Path(nil).GetAttr("input"),
+				})
+				return obj, diags
+			}
+
+			obj.Values = make(map[string][]string)
+			for it := obj.Queries.ElementIterator(); it.Next(); {
+				if client.failedFast(diags) {
+					break
+				}
+				k, v := it.Element()
+				var q xmlDRTQuery
+				err := gocty.FromCtyValue(v, &q)
+				if err != nil {
+					// Should never happen; indicates that our struct is wrong.
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Bug in 'testing' provider",
+						Detail:   fmt.Sprintf("The provider encountered a problem while decoding the query %q block: %s.\n\nThis is a bug in the provider; please report it in the provider's issue tracker.", k.AsString(), err),
+					})
+					continue
+				}
+
+				expr, err := xpath.Compile(q.XPath)
+				if err != nil {
+					// Should never happen because we validated this already.
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Invalid XPath expression",
+						Detail:   fmt.Sprintf("The given XPath expression is invalid: %s.", err),
+						Path:     cty.Path(nil).GetAttr("query").Index(k).GetAttr("xpath"),
+					})
+					continue
+				}
+
+				values, str := evaluateXPath(expr, root)
+				obj.Values[k.AsString()] = values
+
+				statement := ""
+				if q.Statement != nil {
+					if subject != "" {
+						statement = fmt.Sprintf("%s %s", subject, *q.Statement)
+					} else {
+						statement = *q.Statement
+					}
+				}
+
+				if q.Count != nil && *q.Count != len(values) {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   namedAssertionFailureMsg(statement, "count", cty.NumberIntVal(int64(*q.Count)), cty.NumberIntVal(int64(len(values)))),
+						Path:     cty.Path(nil).GetAttr("query").Index(k).GetAttr("count"),
+					})
+				}
+				if q.Want != nil && *q.Want != str {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Test failure",
+						Detail:   namedAssertionFailureMsg(statement, "value", cty.StringVal(*q.Want), cty.StringVal(str)),
+						Path:     cty.Path(nil).GetAttr("query").Index(k).GetAttr("want"),
+					})
+				}
+			}
+
+			return obj, diags
+		},
+	})
+}
+
+// evaluateXPath evaluates the given expression against the given document
+// and returns the string values of all of the selected nodes along with
+// the string value of the overall result.
+//
+// Following the usual XPath conventions, the string value of a node-set is
+// the string value of its first node, or an empty string if it has no nodes.
+// If the expression produces a string, number, or boolean result rather than
+// a node-set then the values slice contains that result as its only element.
+func evaluateXPath(expr *xpath.Expr, root *xmlNode) (values []string, str string) {
+	switch result := expr.Evaluate(newXMLNavigator(root)).(type) {
+	case *xpath.NodeIterator:
+		values = []string{}
+		for result.MoveNext() {
+			values = append(values, result.Current().Value())
+		}
+		if len(values) > 0 {
+			str = values[0]
+		}
+		return values, str
+	case float64:
+		switch {
+		case math.IsNaN(result):
+			str = "NaN"
+		case math.IsInf(result, 1):
+			str = "Infinity"
+		case math.IsInf(result, -1):
+			str = "-Infinity"
+		default:
+			str = strconv.FormatFloat(result, 'f', -1, 64)
+		}
+	case bool:
+		str = strconv.FormatBool(result)
+	case string:
+		str = result
+	default:
+		// Should never happen because the above covers all of the result
+		// types that XPath can produce.
+		str = fmt.Sprint(result)
+	}
+	return []string{str}, str
+}
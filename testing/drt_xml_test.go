@@ -0,0 +1,138 @@
+package testing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDRTXML(t *testing.T) {
+	t.Run("pass", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_xml" "test" {
+  input = <<EOT
+<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:x="urn:example">
+  <title>Example</title>
+  <entry id="a">First</entry>
+  <entry id="b">Second</entry>
+  <x:extra>ns</x:extra>
+</feed>
+EOT
+
+  query "title" {
+	xpath = "/feed/title"
+	want  = "Example"
+  }
+  query "entries" {
+	xpath = "//entry"
+	count = 2
+  }
+  query "ids" {
+	xpath = "//entry/@id"
+  }
+  query "prefixed" {
+	xpath = "/feed/x:extra"
+	want  = "ns"
+  }
+  query "number" {
+	xpath = "count(//entry) + 2"
+	want  = "4"
+  }
+}
+
+data "testing_assertions" "test" {
+  equal "ids" {
+	got  = data.testing_xml.test.values["ids"]
+	want = tolist(["a", "b"])
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("want fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_xml" "test" {
+  input = "<a><b>foo</b></a>"
+
+  query "b" {
+	xpath = "/a/b"
+	want  = "bar"
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("count fail", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_xml" "test" {
+  input = "<a><b>foo</b></a>"
+
+  query "b" {
+	xpath = "/a/b"
+	count = 2
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("invalid xml", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_xml" "test" {
+  input = "<a><b>foo</a>"
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+	t.Run("invalid xpath", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_xml" "test" {
+  input = "<a><b>foo</b></a>"
+
+  query "b" {
+    xpath = "/a/["
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Fatal("succeeded; want error")
+		}
+		if !strings.Contains(err.Error(), "must be a valid XPath expression") {
+			t.Errorf("error does not reject the XPath expression\n%s", err)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type yamlDRT struct {
+	Input string `cty:"input"`
+
+	Result    cty.Value `cty:"result"`
+	Documents cty.Value `cty:"documents"`
+}
+
+func yamlDataResourceType() tfsdk.DataResourceType {
+	return newDataResourceType("testing_yaml", &tfsdk.ResourceTypeDef{
+		ConfigSchema: &tfschema.BlockType{
+			Attributes: map[string]*tfschema.Attribute{
+				"input": {Type: cty.String, Required: true},
+
+				"result":    {Type: cty.DynamicPseudoType, Computed: true},
+				"documents": {Type: cty.DynamicPseudoType, Computed: true},
+			},
+		},
+
+		ReadFn: func(ctx context.Context, client *Client, obj *yamlDRT) (*yamlDRT, tfsdk.Diagnostics) {
+			var diags tfsdk.Diagnostics
+
+			docs, err := parseYAMLDocuments(obj.Input)
+			if err != nil {
+				diags = diags.Append(tfsdk.Diagnostic{
+					Severity: tfsdk.Error,
+					Summary:  "Invalid YAML",
+					Detail:   fmt.Sprintf("The given input is not valid YAML: %s.", err),
+					Path:     cty.Path(nil).GetAttr("input"),
+				})
+				return obj, diags
+			}
+
+			obj.Result = cty.NullVal(cty.DynamicPseudoType)
+			if len(docs) > 0 {
+				obj.Result = docs[0]
+			}
+			obj.Documents = cty.EmptyTupleVal
+			if len(docs) > 0 {
+				obj.Documents = cty.TupleVal(docs)
+			}
+
+			return obj, diags
+		},
+	})
+}
+
+// parseYAMLDocuments parses the given source as a stream of zero or more
+// YAML documents and returns a cty representation of each one.
+//
+// Mappings become object values and sequences become tuple values, so that
+// the result can be destructured using normal Terraform language expressions
+// regardless of how heterogenous the document is.
+func parseYAMLDocuments(src string) ([]cty.Value, error) {
+	var docs []cty.Value
+	dec := yaml.NewDecoder(strings.NewReader(src))
+	for {
+		var raw interface{}
+		err := dec.Decode(&raw)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		v, err := yamlValueToCty(raw, nil)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %s", len(docs), tfsdk.FormatError(err))
+		}
+		docs = append(docs, v)
+	}
+	return docs, nil
+}
+
+func yamlValueToCty(raw interface{}, path cty.Path) (cty.Value, error) {
+	switch raw := raw.(type) {
+	case nil:
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	case string:
+		return cty.StringVal(raw), nil
+	case bool:
+		return cty.BoolVal(raw), nil
+	case int:
+		return cty.NumberIntVal(int64(raw)), nil
+	case int64:
+		return cty.NumberIntVal(raw), nil
+	case uint64:
+		return cty.NumberUIntVal(raw), nil
+	case float64:
+		if math.IsNaN(raw) {
+			return cty.NilVal, path.NewErrorf("NaN is not a valid number")
+		}
+		return cty.NumberFloatVal(raw), nil
+	case time.Time:
+		return cty.StringVal(raw.Format(time.RFC3339Nano)), nil
+	case []interface{}:
+		if len(raw) == 0 {
+			return cty.EmptyTupleVal, nil
+		}
+		vals := make([]cty.Value, len(raw))
+		for i, rawElem := range raw {
+			v, err := yamlValueToCty(rawElem, path.Index(cty.NumberIntVal(int64(i))))
+			if err != nil {
+				return cty.NilVal, err
+			}
+			vals[i] = v
+		}
+		return cty.TupleVal(vals), nil
+	case map[interface{}]interface{}:
+		if len(raw) == 0 {
+			return cty.EmptyObjectVal, nil
+		}
+		attrs := make(map[string]cty.Value, len(raw))
+		for rawKey, rawElem := range raw {
+			var k string
+			switch rawKey := rawKey.(type) {
+			case string:
+				k = rawKey
+			case bool, int, int64, uint64, float64:
+				k = fmt.Sprint(rawKey)
+			default:
+				return cty.NilVal, path.NewErrorf("mapping keys must be scalar values")
+			}
+			if _, exists := attrs[k]; exists {
+				return cty.NilVal, path.NewErrorf("duplicate mapping key %q", k)
+			}
+			v, err := yamlValueToCty(rawElem, path.GetAttr(k))
+			if err != nil {
+				return cty.NilVal, err
+			}
+			attrs[k] = v
+		}
+		return cty.ObjectVal(attrs), nil
+	default:
+		// Should never happen because the above should cover everything
+		// the YAML decoder can produce for an interface{} target.
+		return cty.NilVal, path.NewErrorf("unsupported value of type %T", raw)
+	}
+}
@@ -0,0 +1,92 @@
+package testing
+
+import "testing"
+
+func TestDRTYAML(t *testing.T) {
+	t.Run("single document", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_yaml" "test" {
+  input = <<EOT
+kind: ConfigMap
+metadata:
+  name: example
+data:
+  replicas: 2
+  enabled: true
+EOT
+}
+
+data "testing_assertions" "test" {
+  equal "result" {
+	got  = data.testing_yaml.test.result
+	want = {
+	  kind = "ConfigMap"
+	  metadata = {
+		name = "example"
+	  }
+	  data = {
+		replicas = 2
+		enabled  = true
+	  }
+	}
+  }
+  equal "documents" {
+	got  = length(data.testing_yaml.test.documents)
+	want = 1
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("multiple documents", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_yaml" "test" {
+  input = <<EOT
+---
+name: first
+---
+- a
+- b
+EOT
+}
+
+data "testing_assertions" "test" {
+  equal "first" {
+	got  = data.testing_yaml.test.documents[0].name
+	want = "first"
+  }
+  equal "second" {
+	got  = data.testing_yaml.test.documents[1]
+	want = ["a", "b"]
+  }
+}
+`)
+
+		wd.RequireInit(t)
+		wd.RequireApply(t)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		wd := testHelper.RequireNewWorkingDir(t)
+		defer wd.Close()
+
+		wd.RequireSetConfig(t, `
+data "testing_yaml" "test" {
+  input = "foo: [bar"
+}
+`)
+
+		wd.RequireInit(t)
+		err := wd.Apply()
+		if err == nil {
+			t.Error("succeeded; want error")
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package testing
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomic writes the given content to the file at the given path,
+// creating any missing parent directories.
+//
+// The content is written to a temporary file which then replaces the target,
+// so that a failure part way through can't leave a truncated file behind and
+// other programs never see a partially-written file.
+func writeFileAtomic(path string, content []byte) error {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+base)
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,36 @@
+package testing
+
+import (
+	"fmt"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// convertHCLDiagnostics converts HCL diagnostics from parsing or evaluating
+// an expression given as a string argument into SDK diagnostics with the given
+// path.
+//
+// exprName is a short noun phrase describing the expression, like "condition
+// expression", which is used to give context to source positions.
+func convertHCLDiagnostics(hclDiags hcl.Diagnostics, exprName string, path cty.Path) tfsdk.Diagnostics {
+	var diags tfsdk.Diagnostics
+	for _, hclDiag := range hclDiags {
+		severity := tfsdk.Error
+		if hclDiag.Severity == hcl.DiagWarning {
+			severity = tfsdk.Warning
+		}
+		detail := hclDiag.Detail
+		if hclDiag.Subject != nil {
+			detail = fmt.Sprintf("At column %d of the %s: %s", hclDiag.Subject.Start.Column, exprName, detail)
+		}
+		diags = diags.Append(tfsdk.Diagnostic{
+			Severity: severity,
+			Summary:  hclDiag.Summary,
+			Detail:   detail,
+			Path:     path,
+		})
+	}
+	return diags
+}
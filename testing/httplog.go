@@ -0,0 +1,52 @@
+package testing
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// httpRequestRecord is one entry in an HTTP request log, which is a file
+// containing one JSON-encoded httpRequestRecord per line in the order the
+// requests were received.
+//
+// This is the format that testing_http_mock_requests reads, and so it is also
+// the format that any stub server fixture must write.
+type httpRequestRecord struct {
+	Time    time.Time         `json:"time"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// readHTTPRequestLog reads all of the records from the HTTP request log at
+// the given path.
+func readHTTPRequestLog(path string) ([]httpRequestRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []httpRequestRecord
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var rec httpRequestRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("invalid record on line %d: %s", line, err)
+		}
+		records = append(records, rec)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,220 @@
+package testing
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// kubeconfig is the subset of the kubectl configuration file format that
+// we use to connect to a Kubernetes API server.
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string `yaml:"token"`
+			TokenFile             string `yaml:"tokenFile"`
+			ClientCertificate     string `yaml:"client-certificate"`
+			ClientCertificateData string `yaml:"client-certificate-data"`
+			ClientKey             string `yaml:"client-key"`
+			ClientKeyData         string `yaml:"client-key-data"`
+			Username              string `yaml:"username"`
+			Password              string `yaml:"password"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// kubeClient is a minimal client for the Kubernetes API, prepared from a
+// kubeconfig file by newKubeClient.
+type kubeClient struct {
+	HTTP      *http.Client
+	Server    string
+	Namespace string
+
+	token              string
+	username, password string
+}
+
+// defaultKubeconfigPath returns the kubeconfig file that kubectl would use
+// by default, taking into account the KUBECONFIG environment variable.
+func defaultKubeconfigPath() string {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		// KUBECONFIG can contain a list of files to merge, but we support
+		// only the first one.
+		return filepath.SplitList(env)[0]
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".kube", "config")
+}
+
+// newKubeClient reads the kubeconfig file at the given path and prepares a
+// client for the given context, or for the file's current context if
+// contextName is empty.
+func newKubeClient(path, contextName string) (*kubeClient, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config kubeconfig
+	if err := yaml.Unmarshal(src, &config); err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig file %s: %s", path, err)
+	}
+	baseDir := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(baseDir, p)
+	}
+
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+	if contextName == "" {
+		return nil, fmt.Errorf("kubeconfig file %s does not select a current context", path)
+	}
+	var clusterName, userName, namespace string
+	found := false
+	for _, c := range config.Contexts {
+		if c.Name == contextName {
+			clusterName, userName, namespace = c.Context.Cluster, c.Context.User, c.Context.Namespace
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("kubeconfig file %s has no context named %q", path, contextName)
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	client := &kubeClient{Namespace: namespace}
+	tlsConfig := &tls.Config{}
+
+	found = false
+	for _, c := range config.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		found = true
+		client.Server = strings.TrimRight(c.Cluster.Server, "/")
+		tlsConfig.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify
+
+		var caPEM []byte
+		switch {
+		case c.Cluster.CertificateAuthorityData != "":
+			caPEM, err = base64.StdEncoding.DecodeString(c.Cluster.CertificateAuthorityData)
+		case c.Cluster.CertificateAuthority != "":
+			caPEM, err = ioutil.ReadFile(resolve(c.Cluster.CertificateAuthority))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate authority for cluster %q: %s", clusterName, err)
+		}
+		if caPEM != nil {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(caPEM) {
+				return nil, fmt.Errorf("invalid certificate authority for cluster %q: no PEM-encoded certificates found", clusterName)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		break
+	}
+	if !found {
+		return nil, fmt.Errorf("kubeconfig file %s has no cluster named %q", path, clusterName)
+	}
+
+	for _, u := range config.Users {
+		if u.Name != userName {
+			continue
+		}
+		client.token = u.User.Token
+		if client.token == "" && u.User.TokenFile != "" {
+			token, err := ioutil.ReadFile(resolve(u.User.TokenFile))
+			if err != nil {
+				return nil, fmt.Errorf("invalid token file for user %q: %s", userName, err)
+			}
+			client.token = strings.TrimSpace(string(token))
+		}
+		client.username, client.password = u.User.Username, u.User.Password
+
+		var certPEM, keyPEM []byte
+		switch {
+		case u.User.ClientCertificateData != "":
+			certPEM, err = base64.StdEncoding.DecodeString(u.User.ClientCertificateData)
+		case u.User.ClientCertificate != "":
+			certPEM, err = ioutil.ReadFile(resolve(u.User.ClientCertificate))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate for user %q: %s", userName, err)
+		}
+		switch {
+		case u.User.ClientKeyData != "":
+			keyPEM, err = base64.StdEncoding.DecodeString(u.User.ClientKeyData)
+		case u.User.ClientKey != "":
+			keyPEM, err = ioutil.ReadFile(resolve(u.User.ClientKey))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid client key for user %q: %s", userName, err)
+		}
+		if certPEM != nil || keyPEM != nil {
+			cert, err := tls.X509KeyPair(certPEM, keyPEM)
+			if err != nil {
+				return nil, fmt.Errorf("invalid client certificate for user %q: %s", userName, err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		break
+	}
+
+	client.HTTP = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}
+	return client, nil
+}
+
+// NewRequest prepares a request to the given API path, which must begin with
+// a slash, with the client's credentials attached.
+func (c *kubeClient) NewRequest(method, path string) (*http.Request, error) {
+	req, err := http.NewRequest(method, c.Server+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return req, nil
+}
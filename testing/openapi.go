@@ -0,0 +1,456 @@
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// openAPISpec is an OpenAPI 3 document, kept in its generic decoded form so
+// that schemas can refer to any part of it using $ref.
+type openAPISpec struct {
+	root map[string]interface{}
+}
+
+// openAPIOperation is an operation selected from an OpenAPI document for a
+// particular request.
+type openAPIOperation struct {
+	PathTemplate string
+	Method       string
+	ID           string
+	Responses    map[string]interface{}
+}
+
+// parseOpenAPISpec parses an OpenAPI 3 document given in either JSON or YAML
+// syntax. JSON is a subset of YAML, so we parse both using the YAML parser.
+func parseOpenAPISpec(src string) (*openAPISpec, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal([]byte(src), &raw); err != nil {
+		return nil, err
+	}
+	root, ok := normalizeYAMLValue(raw).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("document root must be an object")
+	}
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("document must declare an OpenAPI 3 version in its \"openapi\" property")
+	}
+	if _, ok := root["paths"].(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("document must have a \"paths\" object")
+	}
+	return &openAPISpec{root: root}, nil
+}
+
+// normalizeYAMLValue converts a value decoded by the YAML parser into the
+// same representation that encoding/json would produce, so that it can be
+// compared with values decoded from JSON responses.
+func normalizeYAMLValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for k, ev := range v {
+			ret[fmt.Sprint(k)] = normalizeYAMLValue(ev)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, ev := range v {
+			ret[i] = normalizeYAMLValue(ev)
+		}
+		return ret
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	default:
+		return v
+	}
+}
+
+// DefaultServerURL returns the URL of the first server declared in the
+// document, or an empty string if there are none.
+func (s *openAPISpec) DefaultServerURL() string {
+	servers, _ := s.root["servers"].([]interface{})
+	if len(servers) == 0 {
+		return ""
+	}
+	server, _ := servers[0].(map[string]interface{})
+	u, _ := server["url"].(string)
+	return u
+}
+
+// FindOperation returns the operation that the given method and path
+// correspond to, or nil if the document does not describe such an operation.
+// The path must be relative to the server URL and must not include a query
+// string.
+func (s *openAPISpec) FindOperation(method, path string) *openAPIOperation {
+	paths := s.root["paths"].(map[string]interface{})
+
+	// If more than one template matches then we prefer the one with the most
+	// literal segments, so that "/pets/mine" is preferred over "/pets/{id}".
+	var best string
+	bestLiterals := -1
+	templates := make([]string, 0, len(paths))
+	for tmpl := range paths {
+		templates = append(templates, tmpl)
+	}
+	sort.Strings(templates)
+	for _, tmpl := range templates {
+		if literals, ok := matchOpenAPIPath(tmpl, path); ok && literals > bestLiterals {
+			best, bestLiterals = tmpl, literals
+		}
+	}
+	if bestLiterals < 0 {
+		return nil
+	}
+
+	item, _ := paths[best].(map[string]interface{})
+	op, ok := item[strings.ToLower(method)].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	id, _ := op["operationId"].(string)
+	responses, _ := op["responses"].(map[string]interface{})
+	return &openAPIOperation{
+		PathTemplate: best,
+		Method:       strings.ToUpper(method),
+		ID:           id,
+		Responses:    responses,
+	}
+}
+
+// matchOpenAPIPath reports whether the given path matches the given path
+// template, and if so how many of the template's segments are literal.
+func matchOpenAPIPath(tmpl, path string) (int, bool) {
+	tmplSegs := strings.Split(strings.Trim(tmpl, "/"), "/")
+	pathSegs := strings.Split(strings.Trim(path, "/"), "/")
+	if len(tmplSegs) != len(pathSegs) {
+		return 0, false
+	}
+	literals := 0
+	for i, ts := range tmplSegs {
+		ps, err := url.PathUnescape(pathSegs[i])
+		if err != nil {
+			return 0, false
+		}
+		if strings.HasPrefix(ts, "{") && strings.HasSuffix(ts, "}") {
+			if ps == "" {
+				return 0, false
+			}
+			continue
+		}
+		if ts != ps {
+			return 0, false
+		}
+		literals++
+	}
+	return literals, true
+}
+
+// ResponseSchema returns the schema that describes the response body for the
+// given status code and media type.
+//
+// The returned error describes why the response is not documented, if it
+// isn't. A nil schema with no error means that the response is documented
+// but its body is not described by a schema.
+func (s *openAPISpec) ResponseSchema(op *openAPIOperation, status int, mediaType string) (interface{}, error) {
+	code := strconv.Itoa(status)
+	resp, ok := op.Responses[code]
+	if !ok {
+		resp, ok = op.Responses[code[:1]+"XX"]
+	}
+	if !ok {
+		resp, ok = op.Responses[code[:1]+"xx"]
+	}
+	if !ok {
+		resp, ok = op.Responses["default"]
+	}
+	if !ok {
+		return nil, fmt.Errorf("status code %d is not documented for %s %s", status, op.Method, op.PathTemplate)
+	}
+	respObj, err := s.resolve(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	content, _ := respObj["content"].(map[string]interface{})
+	if len(content) == 0 || mediaType == "" {
+		return nil, nil
+	}
+	media, ok := content[mediaType]
+	if !ok {
+		if slash := strings.IndexByte(mediaType, '/'); slash > 0 {
+			media, ok = content[mediaType[:slash]+"/*"]
+		}
+	}
+	if !ok {
+		media, ok = content["*/*"]
+	}
+	if !ok {
+		documented := make([]string, 0, len(content))
+		for mt := range content {
+			documented = append(documented, mt)
+		}
+		sort.Strings(documented)
+		return nil, fmt.Errorf("content type %q is not documented for status code %d of %s %s, which allows only %s", mediaType, status, op.Method, op.PathTemplate, strings.Join(documented, ", "))
+	}
+	mediaObj, _ := media.(map[string]interface{})
+	return mediaObj["schema"], nil
+}
+
+// resolve follows a $ref in the given object, if present, returning the
+// object it refers to.
+func (s *openAPISpec) resolve(v interface{}) (map[string]interface{}, error) {
+	for i := 0; i < 32; i++ {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an object in the OpenAPI document")
+		}
+		ref, ok := obj["$ref"].(string)
+		if !ok {
+			return obj, nil
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			return nil, fmt.Errorf("unsupported reference %q: only references within the same document are supported", ref)
+		}
+		v = s.root
+		for _, tok := range strings.Split(ref[2:], "/") {
+			tok = strings.Replace(strings.Replace(tok, "~1", "/", -1), "~0", "~", -1)
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid reference %q", ref)
+			}
+			if v, ok = m[tok]; !ok {
+				return nil, fmt.Errorf("invalid reference %q", ref)
+			}
+		}
+	}
+	return nil, fmt.Errorf("too many nested references")
+}
+
+// ValidateJSON checks the given JSON document against the given schema and
+// returns a description of each problem found, or nil if it conforms.
+func (s *openAPISpec) ValidateJSON(schema interface{}, body []byte) []string {
+	var val interface{}
+	if err := json.Unmarshal(body, &val); err != nil {
+		return []string{fmt.Sprintf("response body is not valid JSON: %s", err)}
+	}
+	var problems []string
+	s.validateValue(schema, val, "$", &problems, 0)
+	return problems
+}
+
+func (s *openAPISpec) validateValue(schemaRaw interface{}, val interface{}, path string, problems *[]string, depth int) {
+	if depth > 64 {
+		*problems = append(*problems, fmt.Sprintf("%s: schema is nested too deeply", path))
+		return
+	}
+	schema, err := s.resolve(schemaRaw)
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: %s", path, err))
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	for _, sub := range openAPISchemaList(schema["allOf"]) {
+		s.validateValue(sub, val, path, problems, depth+1)
+	}
+	if subs := openAPISchemaList(schema["anyOf"]); len(subs) > 0 {
+		matched := 0
+		for _, sub := range subs {
+			var subProblems []string
+			s.validateValue(sub, val, path, &subProblems, depth+1)
+			if len(subProblems) == 0 {
+				matched++
+			}
+		}
+		if matched == 0 {
+			fail("does not match any of the schemas in anyOf")
+		}
+	}
+	if subs := openAPISchemaList(schema["oneOf"]); len(subs) > 0 {
+		matched := 0
+		for _, sub := range subs {
+			var subProblems []string
+			s.validateValue(sub, val, path, &subProblems, depth+1)
+			if len(subProblems) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			fail("matches %d of the schemas in oneOf, but must match exactly one", matched)
+		}
+	}
+
+	if val == nil {
+		nullable, _ := schema["nullable"].(bool)
+		if !nullable && !openAPITypeAllows(schema["type"], "null") && schema["type"] != nil {
+			fail("must not be null")
+		}
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, ev := range enum {
+			if reflect.DeepEqual(ev, val) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of the values in the schema's enum")
+		}
+	}
+
+	switch val := val.(type) {
+	case map[string]interface{}:
+		if !openAPITypeAllows(schema["type"], "object") {
+			fail("must be %s, not an object", openAPITypeName(schema["type"]))
+			return
+		}
+		for _, req := range openAPIStringList(schema["required"]) {
+			if _, ok := val[req]; !ok {
+				fail("missing required property %q", req)
+			}
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			propPath := path + "." + k
+			if propSchema, ok := props[k]; ok {
+				s.validateValue(propSchema, val[k], propPath, problems, depth+1)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					fail("unexpected property %q", k)
+				}
+			case map[string]interface{}:
+				s.validateValue(additional, val[k], propPath, problems, depth+1)
+			}
+		}
+	case []interface{}:
+		if !openAPITypeAllows(schema["type"], "array") {
+			fail("must be %s, not an array", openAPITypeName(schema["type"]))
+			return
+		}
+		if min, ok := schema["minItems"].(float64); ok && float64(len(val)) < min {
+			fail("must have at least %v items", min)
+		}
+		if max, ok := schema["maxItems"].(float64); ok && float64(len(val)) > max {
+			fail("must have at most %v items", max)
+		}
+		if items, ok := schema["items"]; ok {
+			for i, ev := range val {
+				s.validateValue(items, ev, fmt.Sprintf("%s[%d]", path, i), problems, depth+1)
+			}
+		}
+	case string:
+		if !openAPITypeAllows(schema["type"], "string") {
+			fail("must be %s, not a string", openAPITypeName(schema["type"]))
+			return
+		}
+		length := float64(len([]rune(val)))
+		if min, ok := schema["minLength"].(float64); ok && length < min {
+			fail("must be at least %v characters long", min)
+		}
+		if max, ok := schema["maxLength"].(float64); ok && length > max {
+			fail("must be at most %v characters long", max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				fail("schema has invalid pattern %q: %s", pattern, err)
+			} else if !re.MatchString(val) {
+				fail("must match the pattern %q", pattern)
+			}
+		}
+	case float64:
+		isInt := val == float64(int64(val))
+		if !openAPITypeAllows(schema["type"], "number") && !(isInt && openAPITypeAllows(schema["type"], "integer")) {
+			fail("must be %s, not a number", openAPITypeName(schema["type"]))
+			return
+		}
+		if min, ok := schema["minimum"].(float64); ok && val < min {
+			fail("must be at least %v", min)
+		}
+		if max, ok := schema["maximum"].(float64); ok && val > max {
+			fail("must be at most %v", max)
+		}
+	case bool:
+		if !openAPITypeAllows(schema["type"], "boolean") {
+			fail("must be %s, not a boolean", openAPITypeName(schema["type"]))
+		}
+	}
+}
+
+// openAPITypeAllows reports whether the given schema "type" value allows
+// values of the given JSON type. A missing type allows any type, and an
+// "integer" value is also a "number".
+func openAPITypeAllows(typeVal interface{}, want string) bool {
+	if typeVal == nil {
+		return true
+	}
+	for _, t := range openAPIStringList(typeVal) {
+		if t == want || (t == "number" && want == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func openAPITypeName(typeVal interface{}) string {
+	types := openAPIStringList(typeVal)
+	for i, t := range types {
+		switch t {
+		case "object", "array", "integer":
+			types[i] = "an " + t
+		case "null":
+		default:
+			types[i] = "a " + t
+		}
+	}
+	return strings.Join(types, " or ")
+}
+
+// openAPIStringList accepts either a single string or a list of strings, as
+// is allowed for "type" in OpenAPI 3.1.
+func openAPIStringList(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		ret := make([]string, 0, len(v))
+		for _, ev := range v {
+			if s, ok := ev.(string); ok {
+				ret = append(ret, s)
+			}
+		}
+		return ret
+	default:
+		return nil
+	}
+}
+
+func openAPISchemaList(v interface{}) []interface{} {
+	l, _ := v.([]interface{})
+	return l
+}
@@ -0,0 +1,129 @@
+package testing
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// findProcesses returns the ids of all running processes whose executable
+// name is the given name, other than the provider's own process.
+//
+// The executable name is either the kernel's short name for the process or
+// the base name of its first command line argument, because the short name
+// is truncated to 15 bytes.
+func findProcesses(name string) ([]int, error) {
+	entries, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	self := os.Getpid()
+	var pids []int
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == self {
+			continue
+		}
+		dir := filepath.Join("/proc", entry.Name())
+		// The process might exit while we're looking at it, so we just
+		// skip any process we can't read.
+		if comm, err := ioutil.ReadFile(filepath.Join(dir, "comm")); err == nil && strings.TrimSpace(string(comm)) == name {
+			pids = append(pids, pid)
+			continue
+		}
+		if cmdline, err := ioutil.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+			argv0 := strings.SplitN(string(cmdline), "\x00", 2)[0]
+			if argv0 != "" && filepath.Base(argv0) == name {
+				pids = append(pids, pid)
+			}
+		}
+	}
+	sort.Ints(pids)
+	return pids, nil
+}
+
+// processRunning returns true if a process with the given id exists.
+func processRunning(pid int) (bool, error) {
+	_, err := os.Stat(filepath.Join("/proc", strconv.Itoa(pid)))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// processListeningPorts returns the TCP ports that the given processes are
+// listening on, in increasing order.
+//
+// Reading another user's open files requires elevated privileges, so the
+// result may be incomplete unless the processes belong to the same user as
+// the provider.
+func processListeningPorts(pids []int) ([]int, error) {
+	listening := make(map[string]int) // socket inode to port
+	for _, path := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		if err := readListeningSockets(path, listening); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	seen := make(map[int]bool)
+	var ports []int
+	for _, pid := range pids {
+		fdDir := filepath.Join("/proc", strconv.Itoa(pid), "fd")
+		fds, err := ioutil.ReadDir(fdDir)
+		if err != nil {
+			if os.IsPermission(err) {
+				return nil, fmt.Errorf("not permitted to inspect the open files of process %d", pid)
+			}
+			continue // the process has probably exited
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(target, "socket:[") {
+				continue
+			}
+			inode := strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]")
+			if port, ok := listening[inode]; ok && !seen[port] {
+				seen[port] = true
+				ports = append(ports, port)
+			}
+		}
+	}
+	sort.Ints(ports)
+	return ports, nil
+}
+
+// readListeningSockets reads one of the kernel's TCP socket tables and adds
+// the inode and local port of each listening socket to the given map.
+func readListeningSockets(path string, into map[string]int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Scan() // skip the header line
+	for sc.Scan() {
+		// Fields are: sl local_address rem_address st tx_queue:rx_queue
+		// tr:tm->when retrnsmt uid timeout inode ...
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 10 || fields[3] != "0A" { // 0A is TCP_LISTEN
+			continue
+		}
+		i := strings.LastIndexByte(fields[1], ':')
+		if i < 0 {
+			continue
+		}
+		port, err := strconv.ParseUint(fields[1][i+1:], 16, 16)
+		if err != nil {
+			continue
+		}
+		into[fields[9]] = int(port)
+	}
+	return sc.Err()
+}
@@ -0,0 +1,23 @@
+//go:build !linux
+// +build !linux
+
+package testing
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func findProcesses(name string) ([]int, error) {
+	return nil, errProcessUnsupported
+}
+
+func processRunning(pid int) (bool, error) {
+	return false, errProcessUnsupported
+}
+
+func processListeningPorts(pids []int) ([]int, error) {
+	return nil, errProcessUnsupported
+}
+
+var errProcessUnsupported = fmt.Errorf("inspecting processes is not supported on %s", runtime.GOOS)
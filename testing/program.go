@@ -0,0 +1,66 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// programCommand prepares a command to run the given external program, given
+// in the Unix "argv" style, with the given additional environment variables
+// set alongside those that the provider configuration allows it to have.
+//
+// program must have at least one element, which callers should ensure using
+// validation of the corresponding argument.
+func programCommand(ctx context.Context, client *Client, program []string, environment map[string]string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, program[0], program[1:]...)
+	cmd.Env = client.programEnvironment(environment)
+	return cmd
+}
+
+// programEnvironment returns the environment for an external program, in
+// the "key=value" form used by exec.Cmd, given the additional environment
+// variables from the configuration of the data source running it.
+//
+// Variables from the provider's own environment are included only if
+// inherit_environment allows them, and can be overridden by
+// extra_environment and then by the given environment.
+func (c *Client) programEnvironment(environment map[string]string) []string {
+	// env must not be nil even if it ends up empty, because exec.Cmd
+	// treats a nil Env as a request to inherit the whole environment.
+	// exec.Cmd also uses the last value for any duplicated key, so we can
+	// just append the overrides after what we inherit.
+	env := []string{}
+	if c.inheritEnvironment == nil {
+		env = append(env, os.Environ()...)
+	} else {
+		for _, k := range c.inheritEnvironment {
+			if v, ok := os.LookupEnv(k); ok {
+				env = append(env, fmt.Sprintf("%s=%s", k, v))
+			}
+		}
+	}
+	for k, v := range c.extraEnvironment {
+		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	}
+	for k, v := range environment {
+		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	}
+	return env
+}
+
+// validateProgram is a ValidateFn for arguments that specify an external
+// program to run in the Unix "argv" style.
+func validateProgram(v []string) tfsdk.Diagnostics {
+	var diags tfsdk.Diagnostics
+	if len(v) < 1 {
+		diags = diags.Append(tfsdk.ValidationError(
+			cty.Path(nil).NewErrorf("must have at least one element to specify the executable to run"),
+		))
+	}
+	return diags
+}
@@ -2,29 +2,389 @@ package testing
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	tfsdk "github.com/apparentlymart/terraform-sdk"
 	"github.com/apparentlymart/terraform-sdk/tfschema"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func Provider() *tfsdk.Provider {
 	return &tfsdk.Provider{
 		ConfigSchema: &tfschema.BlockType{
-			Attributes: map[string]*tfschema.Attribute{},
+			Attributes: map[string]*tfschema.Attribute{
+				"fail_fast":      {Type: cty.Bool, Optional: true},
+				"report_file":    {Type: cty.String, Optional: true},
+				"report_format":  {Type: cty.String, Optional: true, ValidateFn: validateReportFormat},
+				"results_stream": {Type: cty.String, Optional: true},
+
+				"default_timeout":       {Type: cty.String, Optional: true, ValidateFn: validateDuration},
+				"max_parallel_programs": {Type: cty.Number, Optional: true},
+				"inherit_environment":   {Type: cty.List(cty.String), Optional: true},
+				"extra_environment":     {Type: cty.Map(cty.String), Optional: true},
+
+				"subject_prefix": {Type: cty.String, Optional: true},
+				"tags":           {Type: cty.Map(cty.String), Optional: true},
+
+				"warnings_as_errors": {Type: cty.Bool, Optional: true},
+				"dry_run":            {Type: cty.Bool, Optional: true},
+				"severity_overrides": {
+					Type:       cty.Map(cty.String),
+					Optional:   true,
+					ValidateFn: validateSeverityOverrides,
+				},
+			},
 		},
 		ConfigureFn: func(ctx context.Context, config *Config) (*Client, tfsdk.Diagnostics) {
-			return &Client{}, nil
+			var diags tfsdk.Diagnostics
+			client := &Client{
+				reportFormat: "json",
+			}
+			if config.FailFast != nil {
+				client.failFast = *config.FailFast
+			}
+			if config.ReportFile != nil {
+				client.reportFile = *config.ReportFile
+			}
+			if config.ReportFormat != nil {
+				if config.ReportFile == nil {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Invalid provider configuration",
+						Detail:   "The \"report_format\" argument can be set only when \"report_file\" is also set.",
+						Path:     cty.Path(nil).GetAttr("report_format"),
+					})
+				}
+				client.reportFormat = *config.ReportFormat
+			}
+			if config.ResultsStream != nil {
+				client.resultsStream = &resultsStream{path: *config.ResultsStream}
+			}
+			if config.SubjectPrefix != nil {
+				client.subjectPrefix = *config.SubjectPrefix
+			}
+			client.tags = config.Tags
+			if config.WarningsAsErrors != nil {
+				client.warningsAsErrors = *config.WarningsAsErrors
+			}
+			client.severityOverrides = config.SeverityOverrides
+			if config.DryRun != nil {
+				client.dryRun = *config.DryRun
+			}
+			client.defaultTimeout = durationOrDefault(config.DefaultTimeout, 0)
+			if n := config.MaxParallelPrograms; n != nil {
+				if *n < 1 {
+					diags = diags.Append(tfsdk.Diagnostic{
+						Severity: tfsdk.Error,
+						Summary:  "Invalid provider configuration",
+						Detail:   "The \"max_parallel_programs\" argument must be at least 1.",
+						Path:     cty.Path(nil).GetAttr("max_parallel_programs"),
+					})
+				} else {
+					client.programs = make(chan struct{}, *n)
+				}
+			}
+			client.inheritEnvironment = config.InheritEnvironment
+			client.extraEnvironment = config.ExtraEnvironment
+			return client, diags
 		},
 
-		DataResourceTypes: map[string]tfsdk.DataResourceType{
-			"testing_assertions": assertionsDataResourceType(),
-			"testing_tap":        tapDataResourceType(),
-		},
+		DataResourceTypes: func() map[string]tfsdk.DataResourceType {
+			ret := make(map[string]tfsdk.DataResourceType, len(dataResourceTypes))
+			for name, fn := range dataResourceTypes {
+				ret[name] = fn()
+			}
+			return ret
+		}(),
 	}
 }
 
+// dataResourceTypes are the constructors of the provider's data resource
+// types, by type name.
+var dataResourceTypes = map[string]func() tfsdk.DataResourceType{
+	"testing_assertions":         assertionsDataResourceType,
+	"testing_benchmark":          benchmarkDataResourceType,
+	"testing_baseline_compare":   baselineCompareDataResourceType,
+	"testing_baseline_record":    baselineRecordDataResourceType,
+	"testing_checksum":           checksumDataResourceType,
+	"testing_cloudinit":          cloudinitDataResourceType,
+	"testing_contract":           contractDataResourceType,
+	"testing_dns_consistency":    dnsConsistencyDataResourceType,
+	"testing_docker":             dockerDataResourceType,
+	"testing_env":                envDataResourceType,
+	"testing_gotest":             gotestDataResourceType,
+	"testing_graphql":            graphqlDataResourceType,
+	"testing_grpc_health":        grpcHealthDataResourceType,
+	"testing_http_mock_requests": httpMockRequestsDataResourceType,
+	"testing_jmespath":           jmespathDataResourceType,
+	"testing_junit":              junitDataResourceType,
+	"testing_k8s_ready":          k8sReadyDataResourceType,
+	"testing_log_grep":           logGrepDataResourceType,
+	"testing_openapi":            openAPIDataResourceType,
+	"testing_ping":               pingDataResourceType,
+	"testing_ports":              portsDataResourceType,
+	"testing_process":            processDataResourceType,
+	"testing_prometheus":         prometheusDataResourceType,
+	"testing_regex":              regexDataResourceType,
+	"testing_retry":              retryDataResourceType,
+	"testing_semver":             semverDataResourceType,
+	"testing_smtp":               smtpDataResourceType,
+	"testing_sql":                sqlDataResourceType,
+	"testing_ssh":                sshDataResourceType,
+	"testing_tap":                tapDataResourceType,
+	"testing_terraform_plan":     terraformPlanDataResourceType,
+	"testing_terraform_state":    terraformStateDataResourceType,
+	"testing_time":               timeDataResourceType,
+	"testing_websocket":          websocketDataResourceType,
+	"testing_xml":                xmlDataResourceType,
+	"testing_yaml":               yamlDataResourceType,
+}
+
 type Config struct {
+	FailFast      *bool   `cty:"fail_fast"`
+	ReportFile    *string `cty:"report_file"`
+	ReportFormat  *string `cty:"report_format"`
+	ResultsStream *string `cty:"results_stream"`
+
+	SubjectPrefix *string           `cty:"subject_prefix"`
+	Tags          map[string]string `cty:"tags"`
+
+	WarningsAsErrors  *bool             `cty:"warnings_as_errors"`
+	SeverityOverrides map[string]string `cty:"severity_overrides"`
+	DryRun            *bool             `cty:"dry_run"`
+
+	DefaultTimeout      *string           `cty:"default_timeout"`
+	MaxParallelPrograms *int              `cty:"max_parallel_programs"`
+	InheritEnvironment  []string          `cty:"inherit_environment"`
+	ExtraEnvironment    map[string]string `cty:"extra_environment"`
 }
 
 type Client struct {
+	failFast      bool
+	reportFile    string
+	reportFormat  string
+	resultsStream *resultsStream
+
+	subjectPrefix string
+	tags          map[string]string
+
+	warningsAsErrors  bool
+	severityOverrides map[string]string
+
+	// dryRun reports test failures as warnings, so that they don't cause
+	// Terraform to fail.
+	dryRun bool
+
+	// defaultTimeout, if not zero, is the timeout for data sources that
+	// don't have their own "timeout" argument, and the default for those
+	// that do.
+	defaultTimeout time.Duration
+
+	// programs limits how many external programs can run at once, if
+	// max_parallel_programs is set. Each running program holds one element
+	// of the channel's buffer.
+	programs chan struct{}
+
+	// inheritEnvironment, if not nil, lists the only environment variables
+	// from the provider's own environment that external programs inherit.
+	inheritEnvironment []string
+	extraEnvironment   map[string]string
+
+	mu     sync.Mutex
+	failed bool
+}
+
+// beforeRead is called before reading each data source, and returns true if
+// the read should be skipped, along with a warning explaining why.
+//
+// The skip is only a warning so that the test failure that caused it remains
+// the only error Terraform reports.
+func (c *Client) beforeRead() (skip bool, diags tfsdk.Diagnostics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failFast && c.failed {
+		diags = diags.Append(tfsdk.Diagnostic{
+			Severity: tfsdk.Warning,
+			Summary:  "Test skipped",
+			Detail:   "This data source was not read because another test has already failed and fail_fast is enabled in the provider configuration.",
+		})
+		return true, diags
+	}
+	return false, diags
+}
+
+// failedFast returns true if the given diagnostics, collected so far while
+// reading a data source, already include a test failure and fail_fast is
+// enabled, in which case the data source should stop checking assertions.
+func (c *Client) failedFast(diags tfsdk.Diagnostics) bool {
+	if !c.failFast || c.dryRun {
+		return false
+	}
+	for _, diag := range diags {
+		if isTestFailure(diag) && c.severity(diag) == tfsdk.Error {
+			return true
+		}
+	}
+	return false
+}
+
+// afterRead is called with the result and diagnostics from reading each
+// data source, and returns the diagnostics that should actually be reported.
+func (c *Client) afterRead(result testResult, diags tfsdk.Diagnostics) tfsdk.Diagnostics {
+	c.mu.Lock()
+	var ret tfsdk.Diagnostics
+	failed := false
+	for _, diag := range diags {
+		downgraded := isTestFailure(diag) && c.severity(diag) != tfsdk.Error
+		diag.Severity = c.severity(diag)
+		if downgraded {
+			// severity_overrides has made this failure a warning, so it
+			// doesn't count for fail_fast, but it's still a test failure.
+			diag.Detail = c.decorateFailure(diag.Detail)
+		}
+		if isTestFailure(diag) {
+			if c.failFast && failed {
+				// Most data sources stop checking after their first
+				// failure in fail-fast mode, using failedFast, but some
+				// only learn about several failures at once, such as
+				// the results of a test program, so we drop the rest.
+				continue
+			}
+			failed = true
+			diag.Detail = c.decorateFailure(diag.Detail)
+		}
+		ret = ret.Append(diag)
+	}
+	if c.subjectPrefix != "" {
+		result.Subject = strings.TrimSuffix(c.subjectPrefix+": "+result.Subject, ": ")
+	}
+	result.Tags = c.tags
+	result.setDiagnostics(ret)
+
+	if failed && c.dryRun {
+		// The report still counts the failures, but Terraform sees only
+		// warnings. We don't record the failure for fail_fast either,
+		// because skipping the remaining tests would fail the run.
+		result.DryRun = true
+		for i, diag := range ret {
+			if isTestFailure(diag) {
+				ret[i].Severity = tfsdk.Warning
+				ret[i].Summary = "Test failure (dry run)"
+			}
+		}
+	} else if failed {
+		c.failed = true
+	}
+
+	if c.reportFile != "" {
+		if err := writeReport(c.reportFile, c.reportFormat, result); err != nil {
+			ret = ret.Append(tfsdk.Diagnostic{
+				Severity: tfsdk.Error,
+				Summary:  "Failed to write test report",
+				Detail:   fmt.Sprintf("Error writing the report file %s configured for the provider: %s.", c.reportFile, err),
+			})
+		}
+	}
+	c.mu.Unlock()
+
+	// The results stream has its own lock, because writing to a named pipe
+	// can wait for its reader, which shouldn't hold up other data sources.
+	if c.resultsStream != nil {
+		if err := c.resultsStream.write(result); err != nil {
+			ret = ret.Append(tfsdk.Diagnostic{
+				Severity: tfsdk.Error,
+				Summary:  "Failed to write test result",
+				Detail:   fmt.Sprintf("Error writing to the results stream %s configured for the provider: %s.", c.resultsStream.path, err),
+			})
+		}
+	}
+	return ret
+}
+
+// timeout returns the duration given in the "timeout" argument of a data
+// source, or the default timeout from the provider configuration if the
+// argument isn't set. def is the data source's own default, used if
+// neither is set.
+func (c *Client) timeout(raw *string, def time.Duration) time.Duration {
+	if c.defaultTimeout != 0 {
+		def = c.defaultTimeout
+	}
+	return durationOrDefault(raw, def)
+}
+
+// acquireProgram blocks until the provider may start another external
+// program, and then returns a function that must be called once the program
+// has exited. It returns an error only if the given context is cancelled
+// while waiting.
+func (c *Client) acquireProgram(ctx context.Context) (release func(), err error) {
+	if c.programs == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.programs <- struct{}{}:
+		return func() { <-c.programs }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// severity returns the severity that the given diagnostic should be
+// reported with, taking into account the severity settings from the
+// provider configuration.
+func (c *Client) severity(diag tfsdk.Diagnostic) tfsdk.DiagSeverity {
+	switch c.severityOverrides[diag.Summary] {
+	case "error":
+		return tfsdk.Error
+	case "warning":
+		return tfsdk.Warning
+	}
+	// Skipped tests stay warnings, because otherwise fail_fast would turn
+	// one failure into an error for every data source read after it.
+	if c.warningsAsErrors && diag.Severity == tfsdk.Warning && diag.Summary != "Test skipped" {
+		return tfsdk.Error
+	}
+	return diag.Severity
+}
+
+func validateSeverityOverrides(v map[string]string) tfsdk.Diagnostics {
+	var diags tfsdk.Diagnostics
+	for summary, severity := range v {
+		if severity != "error" && severity != "warning" {
+			diags = diags.Append(tfsdk.ValidationError(
+				cty.Path(nil).Index(cty.StringVal(summary)).NewErrorf("must be \"error\" or \"warning\""),
+			))
+		}
+	}
+	return diags
+}
+
+// decorateFailure adds the subject prefix and tags from the provider
+// configuration, if any, to the detail message of a test failure.
+func (c *Client) decorateFailure(detail string) string {
+	if c.subjectPrefix != "" {
+		detail = fmt.Sprintf("%s: %s", c.subjectPrefix, detail)
+	}
+	if len(c.tags) != 0 {
+		detail = fmt.Sprintf("%s\n\nTags: %s", detail, formatTags(c.tags))
+	}
+	return detail
+}
+
+// formatTags returns the given tags as a comma-separated list of key=value
+// pairs, sorted by key.
+func formatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%s", k, tags[k])
+	}
+	return strings.Join(pairs, ", ")
 }
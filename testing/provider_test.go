@@ -1,8 +1,10 @@
 package testing
 
 import (
+	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/apparentlymart/terraform-sdk/tftest"
 )
@@ -15,3 +17,93 @@ func TestMain(m *testing.M) {
 	testHelper.Close()
 	os.Exit(status)
 }
+
+func TestClientAcquireProgram(t *testing.T) {
+	client := &Client{programs: make(chan struct{}, 1)}
+
+	release, err := client.acquireProgram(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// With the only slot taken, a second program must wait until the
+	// context is cancelled.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.acquireProgram(ctx); err == nil {
+		t.Fatal("acquired a second slot; want error")
+	}
+
+	release()
+	release, err = client.acquireProgram(context.Background())
+	if err != nil {
+		t.Fatalf("failed to acquire the released slot: %s", err)
+	}
+	release()
+
+	// Without a limit, there's no need to wait at all.
+	client = &Client{}
+	for i := 0; i < 3; i++ {
+		if _, err := client.acquireProgram(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestProviderEnvironment(t *testing.T) {
+	os.Setenv("TF_TESTING_INHERITED", "inherited")
+	os.Setenv("TF_TESTING_SECRET", "secret")
+	defer os.Unsetenv("TF_TESTING_INHERITED")
+	defer os.Unsetenv("TF_TESTING_SECRET")
+
+	wd := testHelper.RequireNewWorkingDir(t)
+	defer wd.Close()
+
+	wd.RequireSetConfig(t, `
+provider "testing" {
+  inherit_environment = ["TF_TESTING_INHERITED"]
+  extra_environment = {
+    TF_TESTING_EXTRA    = "extra"
+    TF_TESTING_OVERRIDE = "provider"
+  }
+}
+
+data "testing_tap" "test" {
+  program = ["sh", "-c", <<-EOT
+    echo 1..4
+    [ "$TF_TESTING_INHERITED" = inherited ] && echo ok 1 || echo not ok 1 - inherited
+    [ -z "$TF_TESTING_SECRET" ] && echo ok 2 || echo not ok 2 - secret
+    [ "$TF_TESTING_EXTRA" = extra ] && echo ok 3 || echo not ok 3 - extra
+    [ "$TF_TESTING_OVERRIDE" = data ] && echo ok 4 || echo not ok 4 - override
+  EOT
+  ]
+  environment = {
+    TF_TESTING_OVERRIDE = "data"
+  }
+}
+`)
+
+	wd.RequireInit(t)
+	wd.RequireApply(t)
+}
+
+func TestClientTimeout(t *testing.T) {
+	local := "5s"
+	tests := map[string]struct {
+		Default time.Duration
+		Raw     *string
+		Want    time.Duration
+	}{
+		"data source default": {0, nil, time.Minute},
+		"provider default":    {time.Second, nil, time.Second},
+		"local":               {time.Second, &local, 5 * time.Second},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &Client{defaultTimeout: test.Default}
+			if got := client.timeout(test.Raw, time.Minute); got != test.Want {
+				t.Errorf("wrong timeout %s; want %s", got, test.Want)
+			}
+		})
+	}
+}
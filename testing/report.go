@@ -0,0 +1,464 @@
+package testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/zclconf/go-cty/cty"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// testResult is the outcome of reading one data source, as recorded in the
+// report file configured for the provider.
+type testResult struct {
+	DataSource string            `json:"data_source"`
+	Subject    string            `json:"subject,omitempty"`
+	Status     string            `json:"status"`
+	Messages   []string          `json:"messages,omitempty"`
+	Warnings   []string          `json:"warnings,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Time       time.Time         `json:"time"`
+	DurationMS float64           `json:"duration_ms"`
+
+	// DryRun is set if the provider reported the test failures in this
+	// result as warnings, because dry_run is enabled.
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// Downgraded is set if the provider reported the test failures in this
+	// result as warnings, because of severity_overrides.
+	Downgraded bool `json:"downgraded,omitempty"`
+
+	// Assertions are the outcomes of the individual assertions checked by
+	// data sources that have several, such as testing_assertions.
+	Assertions []assertionResult `json:"assertions,omitempty"`
+}
+
+// assertionResult is the outcome of one assertion within a testResult.
+type assertionResult struct {
+	// ID is the assertion's id argument, if it has one.
+	ID string `json:"id,omitempty" yaml:"id,omitempty"`
+
+	// Name identifies the assertion by its block type and label, like
+	// `equal "content_type"`.
+	Name string `json:"name" yaml:"name"`
+
+	// Status is testResultPass, testResultFail, or testResultError.
+	Status string `json:"status" yaml:"status"`
+}
+
+func (a assertionResult) String() string {
+	if a.ID != "" {
+		return fmt.Sprintf("%s (id %q)", a.Name, a.ID)
+	}
+	return a.Name
+}
+
+// Valid values for testResult.Status.
+const (
+	testResultPass  = "pass"
+	testResultFail  = "fail"
+	testResultError = "error"
+	testResultSkip  = "skip"
+)
+
+// setDiagnostics sets the status and messages of the receiver from the
+// diagnostics returned by reading a data source.
+func (r *testResult) setDiagnostics(diags tfsdk.Diagnostics) {
+	r.Status = testResultPass
+	r.Messages = nil
+	r.Warnings = nil
+	for _, diag := range diags {
+		msg := diag.Summary
+		if diag.Detail != "" {
+			msg = fmt.Sprintf("%s: %s", diag.Summary, diag.Detail)
+		}
+		switch {
+		case diag.Summary == "Test skipped":
+			// Skipped tests are reported only as warnings, so that the
+			// failure that caused the skip is the only error.
+			r.Status = testResultSkip
+		case diag.Summary == "Test failure" && diag.Severity != tfsdk.Error:
+			// A test failure that severity_overrides made a warning still
+			// counts as a failure in the report.
+			r.Downgraded = true
+			if r.Status == testResultPass {
+				r.Status = testResultFail
+			}
+		case diag.Severity != tfsdk.Error:
+			r.Warnings = append(r.Warnings, msg)
+			continue
+		case isTestFailure(diag):
+			if r.Status == testResultPass {
+				r.Status = testResultFail
+			}
+		default:
+			r.Status = testResultError
+		}
+		r.Messages = append(r.Messages, msg)
+	}
+}
+
+// name returns a name for the result for use in reports, which includes the
+// subject of the test if the data source has one.
+func (r *testResult) name() string {
+	if r.Subject != "" {
+		return fmt.Sprintf("%s: %s", r.DataSource, r.Subject)
+	}
+	return r.DataSource
+}
+
+// validReportFormats are the accepted values of the provider's
+// report_format argument.
+var validReportFormats = map[string]func([]testResult) ([]byte, error){
+	"github": githubReport,
+	"json":   jsonReport,
+	"junit":  junitReport,
+	"tap":    tapReport,
+}
+
+func validateReportFormat(v string) tfsdk.Diagnostics {
+	var diags tfsdk.Diagnostics
+	if _, ok := validReportFormats[v]; !ok {
+		diags = diags.Append(tfsdk.ValidationError(
+			cty.Path(nil).NewErrorf("must be \"github\", \"json\", \"junit\", or \"tap\""),
+		))
+	}
+	return diags
+}
+
+// reportState is the content of a file that accumulates test results for
+// the report file.
+//
+// Terraform starts a separate provider process for each phase of a single
+// command, such as refresh and plan, so the results must be kept on disk to
+// produce one report for the whole command. The report file itself is
+// rewritten after each result, and so is always complete once Terraform
+// exits.
+type reportState struct {
+	// TerraformPID is the process id of the Terraform CLI process that the
+	// results belong to. Results from an earlier command are discarded.
+	TerraformPID int          `json:"terraform_pid"`
+	Results      []testResult `json:"results"`
+}
+
+// reportStatePath returns the path of the file where results are
+// accumulated for the given report file.
+func reportStatePath(reportFile string) string {
+	dir, base := filepath.Split(reportFile)
+	return filepath.Join(dir, "."+base+".state")
+}
+
+// writeReport adds the given result to those already recorded for the
+// current Terraform command and then rewrites the given report file.
+func writeReport(reportFile, format string, result testResult) error {
+	statePath := reportStatePath(reportFile)
+	var state reportState
+	if buf, err := ioutil.ReadFile(statePath); err == nil {
+		// If the state file is corrupt then we'll just start over.
+		json.Unmarshal(buf, &state)
+	}
+	if pid := os.Getppid(); state.TerraformPID != pid {
+		state = reportState{TerraformPID: pid}
+	}
+	state.Results = append(state.Results, result)
+
+	buf, err := json.Marshal(&state)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(statePath, buf); err != nil {
+		return err
+	}
+	report, err := validReportFormats[format](state.Results)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(reportFile, report)
+}
+
+// resultsStream appends each result written to it to the file configured
+// as the provider's results_stream, as a single line of JSON.
+//
+// The file might be a named pipe, whose reader could come and go. The
+// stream opens it without blocking, so that a missing reader can't stall
+// the provider, and drops results while there is no reader.
+type resultsStream struct {
+	path string
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+// write appends the given result to the stream, opening the file first if
+// necessary.
+func (s *resultsStream) write(result testResult) error {
+	event := struct {
+		Type         string `json:"type"`
+		TerraformPID int    `json:"terraform_pid"`
+		testResult
+	}{
+		Type:         "result",
+		TerraformPID: os.Getppid(),
+		testResult:   result,
+	}
+	buf, err := json.Marshal(&event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		// Opening a named pipe for writing blocks until there is a reader,
+		// unless we ask for non-blocking mode, in which case it fails
+		// with ENXIO instead.
+		f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE|syscall.O_NONBLOCK, 0644)
+		if errors.Is(err, syscall.ENXIO) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.f = f
+	}
+
+	// A single write call for the whole line means that lines from
+	// concurrent writers won't be interleaved.
+	_, err = s.f.Write(append(buf, '\n'))
+	if errors.Is(err, syscall.EPIPE) {
+		// The reader of a named pipe has gone away, so we'll open it
+		// again for the next result in case another reader has arrived.
+		s.f.Close()
+		s.f = nil
+		return nil
+	}
+	return err
+}
+
+func jsonReport(results []testResult) ([]byte, error) {
+	report := struct {
+		Passed  int          `json:"passed"`
+		Failed  int          `json:"failed"`
+		Errors  int          `json:"errors"`
+		Skipped int          `json:"skipped"`
+		Results []testResult `json:"results"`
+	}{
+		Results: results,
+	}
+	for _, r := range results {
+		switch r.Status {
+		case testResultPass:
+			report.Passed++
+		case testResultFail:
+			report.Failed++
+		case testResultError:
+			report.Errors++
+		case testResultSkip:
+			report.Skipped++
+		}
+	}
+	buf, err := json.MarshalIndent(&report, "", "  ")
+	return append(buf, '\n'), err
+}
+
+type junitReportSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suite   junitReportSuite `xml:"testsuite"`
+}
+
+type junitReportSuite struct {
+	Name       string                `xml:"name,attr"`
+	Tests      int                   `xml:"tests,attr"`
+	Failures   int                   `xml:"failures,attr"`
+	Errors     int                   `xml:"errors,attr"`
+	Skipped    int                   `xml:"skipped,attr"`
+	Time       float64               `xml:"time,attr"`
+	Properties []junitReportProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase       `xml:"testcase"`
+}
+
+type junitReportProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+func junitReport(results []testResult) ([]byte, error) {
+	suite := junitReportSuite{
+		Name:      "terraform-provider-testing",
+		Tests:     len(results),
+		TestCases: make([]junitTestCase, len(results)),
+	}
+	for i, r := range results {
+		tc := junitTestCase{
+			ClassName: r.DataSource,
+			Name:      r.name(),
+			Time:      r.DurationMS / 1000,
+		}
+		for _, a := range r.Assertions {
+			tc.Properties = append(tc.Properties, junitReportProperty{Name: a.String(), Value: a.Status})
+		}
+		var detail *junitDetail
+		if len(r.Messages) != 0 {
+			detail = &junitDetail{
+				Message: strings.SplitN(r.Messages[0], "\n", 2)[0],
+				Text:    strings.Join(r.Messages, "\n\n"),
+			}
+		}
+		switch r.Status {
+		case testResultFail:
+			suite.Failures++
+			tc.Failure = detail
+		case testResultError:
+			suite.Errors++
+			tc.Error = detail
+		case testResultSkip:
+			suite.Skipped++
+			tc.Skipped = detail
+		}
+		suite.Time += tc.Time
+		suite.TestCases[i] = tc
+	}
+
+	// The tags are the same for all results, because they come from the
+	// provider configuration, so we report them for the suite as a whole.
+	if len(results) != 0 {
+		tags := results[0].Tags
+		keys := make([]string, 0, len(tags))
+		for k := range tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			suite.Properties = append(suite.Properties, junitReportProperty{Name: k, Value: tags[k]})
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(&junitReportSuites{Suite: suite}); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func tapReport(results []testResult) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "TAP version 13\n1..%d\n", len(results))
+	for i, r := range results {
+		// "#" begins a directive in TAP, so it can't appear in descriptions.
+		desc := strings.Replace(r.name(), "#", `\#`, -1)
+		switch r.Status {
+		case testResultPass:
+			fmt.Fprintf(&buf, "ok %d - %s\n", i+1, desc)
+			if len(r.Assertions) == 0 {
+				continue
+			}
+		case testResultSkip:
+			fmt.Fprintf(&buf, "ok %d - %s # SKIP\n", i+1, desc)
+			continue
+		case testResultFail:
+			if r.DryRun {
+				// A TODO directive is TAP's way to report a failure that
+				// doesn't count against the run.
+				fmt.Fprintf(&buf, "not ok %d - %s # TODO dry run\n", i+1, desc)
+				break
+			}
+			if r.Downgraded {
+				fmt.Fprintf(&buf, "not ok %d - %s # TODO downgraded\n", i+1, desc)
+				break
+			}
+			fmt.Fprintf(&buf, "not ok %d - %s\n", i+1, desc)
+		default:
+			fmt.Fprintf(&buf, "not ok %d - %s\n", i+1, desc)
+		}
+
+		fields := map[string]interface{}{
+			"status": r.Status,
+		}
+		if len(r.Messages) != 0 {
+			fields["messages"] = r.Messages
+		}
+		if len(r.Tags) != 0 {
+			fields["tags"] = r.Tags
+		}
+		if len(r.Assertions) != 0 {
+			fields["assertions"] = r.Assertions
+		}
+		diag, err := yaml.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("  ---\n")
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(string(diag), "\n"), "\n") {
+			buf.WriteString("  " + line)
+		}
+		buf.WriteString("\n  ...\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// githubReport produces GitHub Actions workflow commands that create an
+// annotation for each error and warning, and a log group listing the
+// individual assertions of each data source, for a workflow step to print
+// after running Terraform.
+//
+// Terraform doesn't tell providers where in the configuration a data source
+// is declared, so the annotations can't refer to a particular file and line.
+func githubReport(results []testResult) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, r := range results {
+		command := "error"
+		switch {
+		case r.Status == testResultSkip:
+			command = "notice"
+		case (r.DryRun || r.Downgraded) && r.Status == testResultFail:
+			command = "warning"
+		}
+		title := githubCommandEscape(r.name(), true)
+		for _, msg := range r.Messages {
+			fmt.Fprintf(&buf, "::%s title=%s::%s\n", command, title, githubCommandEscape(msg, false))
+		}
+		for _, msg := range r.Warnings {
+			fmt.Fprintf(&buf, "::warning title=%s::%s\n", title, githubCommandEscape(msg, false))
+		}
+		if len(r.Assertions) != 0 {
+			// The individual assertions aren't worth an annotation each,
+			// so we list them in a collapsible group in the log instead.
+			fmt.Fprintf(&buf, "::group::%s\n", githubCommandEscape(r.name(), false))
+			for _, a := range r.Assertions {
+				fmt.Fprintf(&buf, "%s: %s\n", a.Status, a.String())
+			}
+			buf.WriteString("::endgroup::\n")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// githubCommandEscape escapes the given string for inclusion in a GitHub
+// Actions workflow command, either as the value of a property or as the
+// command's message.
+func githubCommandEscape(s string, property bool) string {
+	s = strings.Replace(s, "%", "%25", -1)
+	s = strings.Replace(s, "\r", "%0D", -1)
+	s = strings.Replace(s, "\n", "%0A", -1)
+	if property {
+		s = strings.Replace(s, ":", "%3A", -1)
+		s = strings.Replace(s, ",", "%2C", -1)
+	}
+	return s
+}
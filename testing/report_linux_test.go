@@ -0,0 +1,65 @@
+package testing
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestResultsStreamPipe(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-testing-results")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "results")
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	stream := &resultsStream{path: path}
+	result := testResult{DataSource: "testing_assertions", Status: testResultPass}
+
+	// With no reader, the result is dropped rather than waiting for one.
+	if err := stream.write(result); err != nil {
+		t.Fatalf("unexpected error with no reader: %s", err)
+	}
+	if stream.f != nil {
+		t.Fatal("stream is open with no reader")
+	}
+
+	r, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.write(result); err != nil {
+		t.Fatalf("unexpected error with a reader: %s", err)
+	}
+	line, err := bufio.NewReader(r).ReadBytes('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	var event struct {
+		Type       string `json:"type"`
+		DataSource string `json:"data_source"`
+	}
+	if err := json.Unmarshal(line, &event); err != nil {
+		t.Fatalf("invalid event: %s\n%s", err, line)
+	}
+	if event.Type != "result" || event.DataSource != "testing_assertions" {
+		t.Errorf("wrong event %s", line)
+	}
+
+	// Once the reader goes away, the stream drops results until it can
+	// open the pipe again.
+	r.Close()
+	if err := stream.write(result); err != nil {
+		t.Fatalf("unexpected error after the reader closed: %s", err)
+	}
+	if stream.f != nil {
+		t.Error("stream is still open after the reader closed")
+	}
+}
@@ -0,0 +1,434 @@
+package testing
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+)
+
+var testReportResults = []testResult{
+	{
+		DataSource: "testing_assertions",
+		Subject:    "greeting",
+		Status:     testResultPass,
+		Time:       time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC),
+		DurationMS: 1500,
+		Assertions: []assertionResult{
+			{ID: "hello", Name: `check "greets"`, Status: testResultPass},
+			{Name: `equal "name"`, Status: testResultPass},
+		},
+	},
+	{
+		DataSource: "testing_tap",
+		Status:     testResultFail,
+		Messages:   []string{"Test failure: Assertion failed: #1 works.\n  details"},
+		Tags:       map[string]string{"env": "ci"},
+		Time:       time.Date(2019, 4, 1, 12, 0, 1, 0, time.UTC),
+		DurationMS: 500,
+	},
+	{
+		DataSource: "testing_http_mock_requests",
+		Status:     testResultSkip,
+		Messages:   []string{"Test skipped: an earlier test failed."},
+		Time:       time.Date(2019, 4, 1, 12, 0, 2, 0, time.UTC),
+	},
+}
+
+func TestTestResultSetDiagnostics(t *testing.T) {
+	tests := map[string]struct {
+		Diags tfsdk.Diagnostics
+		Want  string
+	}{
+		"none": {nil, testResultPass},
+		"warning": {
+			tfsdk.Diagnostics{{Severity: tfsdk.Warning, Summary: "Unknown key"}},
+			testResultPass,
+		},
+		"failure": {
+			tfsdk.Diagnostics{{Severity: tfsdk.Error, Summary: "Test failure"}},
+			testResultFail,
+		},
+		"error": {
+			tfsdk.Diagnostics{
+				{Severity: tfsdk.Error, Summary: "Test failure"},
+				{Severity: tfsdk.Error, Summary: "Failed to connect"},
+			},
+			testResultError,
+		},
+		"skipped": {
+			tfsdk.Diagnostics{{Severity: tfsdk.Warning, Summary: "Test skipped"}},
+			testResultSkip,
+		},
+		"downgraded failure": {
+			tfsdk.Diagnostics{{Severity: tfsdk.Warning, Summary: "Test failure"}},
+			testResultFail,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var r testResult
+			r.setDiagnostics(test.Diags)
+			if r.Status != test.Want {
+				t.Errorf("wrong status %q; want %q", r.Status, test.Want)
+			}
+		})
+	}
+}
+
+func TestTAPReport(t *testing.T) {
+	got, err := tapReport(testReportResults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `TAP version 13
+1..3
+ok 1 - testing_assertions: greeting
+  ---
+  assertions:
+  - id: hello
+    name: check "greets"
+    status: pass
+  - name: equal "name"
+    status: pass
+  status: pass
+  ...
+not ok 2 - testing_tap
+  ---
+  messages:
+  - |-
+    Test failure: Assertion failed: #1 works.
+      details
+  status: fail
+  tags:
+    env: ci
+  ...
+ok 3 - testing_http_mock_requests # SKIP
+`
+	if string(got) != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestJUnitReport(t *testing.T) {
+	got, err := junitReport(testReportResults)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// We parse the report using the same structures as testing_junit, to
+	// make sure the result is something we can read ourselves.
+	var suites junitTestSuites
+	if err := xml.Unmarshal(got, &suites); err != nil {
+		t.Fatalf("invalid XML: %s\n%s", err, got)
+	}
+	if len(suites.Suites) != 1 {
+		t.Fatalf("wrong number of suites %d; want 1\n%s", len(suites.Suites), got)
+	}
+	cases := suites.Suites[0].TestCases
+	if len(cases) != 3 {
+		t.Fatalf("wrong number of test cases %d; want 3\n%s", len(cases), got)
+	}
+	if got, want := cases[0].Name, "testing_assertions: greeting"; got != want {
+		t.Errorf("wrong name %q; want %q", got, want)
+	}
+	if got, want := cases[0].Time, 1.5; got != want {
+		t.Errorf("wrong time %v; want %v", got, want)
+	}
+	wantProps := []junitReportProperty{
+		{Name: `check "greets" (id "hello")`, Value: "pass"},
+		{Name: `equal "name"`, Value: "pass"},
+	}
+	if got := cases[0].Properties; !reflect.DeepEqual(got, wantProps) {
+		t.Errorf("wrong properties\ngot:  %#v\nwant: %#v", got, wantProps)
+	}
+	if cases[1].Failure == nil {
+		t.Fatalf("second test case has no failure")
+	}
+	if got, want := cases[1].Failure.Message, "Test failure: Assertion failed: #1 works."; got != want {
+		t.Errorf("wrong failure message %q; want %q", got, want)
+	}
+	if cases[2].Skipped == nil {
+		t.Errorf("third test case is not skipped")
+	}
+}
+
+func TestProviderReportFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-testing-report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	reportFile := filepath.Join(dir, "report.json")
+
+	wd := testHelper.RequireNewWorkingDir(t)
+	defer wd.Close()
+
+	wd.RequireSetConfig(t, fmt.Sprintf(`
+provider "testing" {
+  report_file   = %q
+  report_format = "json"
+}
+
+data "testing_assertions" "pass" {
+  subject = "first"
+
+  check "a" {
+    expect = true
+  }
+}
+
+data "testing_regex" "pass" {
+  input   = "abc"
+  pattern = "^a"
+}
+
+data "testing_assertions" "fail" {
+  check "a" {
+    id     = "falsy"
+    expect = false
+  }
+  check "b" {
+    expect = true
+  }
+}
+`, reportFile))
+
+	wd.RequireInit(t)
+
+	// We run twice to make sure the second run replaces the results of the
+	// first, rather than adding to them.
+	for i := 0; i < 2; i++ {
+		if err := wd.Apply(); err == nil {
+			t.Fatal("succeeded; want error")
+		}
+
+		buf, err := ioutil.ReadFile(reportFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var report struct {
+			Passed  int          `json:"passed"`
+			Failed  int          `json:"failed"`
+			Results []testResult `json:"results"`
+		}
+		if err := json.Unmarshal(buf, &report); err != nil {
+			t.Fatalf("invalid report: %s\n%s", err, buf)
+		}
+		if report.Passed != 2 || report.Failed != 1 {
+			t.Errorf("run %d: wrong counts %d passed, %d failed; want 2 passed, 1 failed\n%s", i+1, report.Passed, report.Failed, buf)
+		}
+		found := false
+		for _, r := range report.Results {
+			if r.DataSource == "testing_assertions" && r.Subject == "first" {
+				found = true
+			}
+			if r.DataSource == "testing_assertions" && r.Status == testResultFail {
+				want := []assertionResult{
+					{ID: "falsy", Name: `check "a"`, Status: testResultFail},
+					{Name: `check "b"`, Status: testResultPass},
+				}
+				if !reflect.DeepEqual(r.Assertions, want) {
+					t.Errorf("run %d: wrong assertions\ngot:  %#v\nwant: %#v", i+1, r.Assertions, want)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("run %d: no result for the testing_assertions data source with subject \"first\"\n%s", i+1, buf)
+		}
+	}
+}
+
+func TestGitHubReport(t *testing.T) {
+	results := append([]testResult{
+		{
+			DataSource: "testing_tap",
+			Subject:    "50% done, nearly",
+			Status:     testResultPass,
+			Warnings:   []string{"Test passed unexpectedly: Bonus test pass: #2."},
+		},
+	}, testReportResults...)
+	got, err := githubReport(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `::warning title=testing_tap%3A 50%25 done%2C nearly::Test passed unexpectedly: Bonus test pass: #2.
+::group::testing_assertions: greeting
+pass: check "greets" (id "hello")
+pass: equal "name"
+::endgroup::
+::error title=testing_tap::Test failure: Assertion failed: #1 works.%0A  details
+::notice title=testing_http_mock_requests::Test skipped: an earlier test failed.
+`
+	if string(got) != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestProviderResultsStream(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-testing-results")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stream := filepath.Join(dir, "results.jsonl")
+
+	wd := testHelper.RequireNewWorkingDir(t)
+	defer wd.Close()
+
+	wd.RequireSetConfig(t, fmt.Sprintf(`
+provider "testing" {
+  results_stream = %q
+}
+
+data "testing_assertions" "pass" {
+  subject = "first"
+
+  check "a" {
+    expect = true
+  }
+}
+
+data "testing_regex" "pass" {
+  input   = "abc"
+  pattern = "^a"
+}
+`, stream))
+
+	wd.RequireInit(t)
+	wd.RequireApply(t)
+
+	buf, err := ioutil.ReadFile(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(buf)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("wrong number of events %d; want 2\n%s", len(lines), buf)
+	}
+	got := map[string]string{}
+	for _, line := range lines {
+		var event struct {
+			Type       string `json:"type"`
+			DataSource string `json:"data_source"`
+			Status     string `json:"status"`
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("invalid event: %s\n%s", err, line)
+		}
+		if event.Type != "result" {
+			t.Errorf("wrong event type %q; want \"result\"", event.Type)
+		}
+		got[event.DataSource] = event.Status
+	}
+	want := map[string]string{
+		"testing_assertions": testResultPass,
+		"testing_regex":      testResultPass,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong results\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestProviderDryRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-testing-report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	reportFile := filepath.Join(dir, "report.json")
+
+	wd := testHelper.RequireNewWorkingDir(t)
+	defer wd.Close()
+
+	wd.RequireSetConfig(t, fmt.Sprintf(`
+provider "testing" {
+  dry_run     = true
+  fail_fast   = true
+  report_file = %q
+}
+
+data "testing_assertions" "fail" {
+  check "a" {
+    expect = false
+  }
+}
+
+data "testing_assertions" "also_fail" {
+  check "a" {
+    expect = false
+  }
+}
+`, reportFile))
+
+	wd.RequireInit(t)
+	wd.RequireApply(t)
+
+	buf, err := ioutil.ReadFile(reportFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Failed  int          `json:"failed"`
+		Results []testResult `json:"results"`
+	}
+	if err := json.Unmarshal(buf, &report); err != nil {
+		t.Fatalf("invalid report: %s\n%s", err, buf)
+	}
+	if report.Failed != 2 {
+		t.Errorf("wrong number of failures %d; want 2\n%s", report.Failed, buf)
+	}
+	for _, r := range report.Results {
+		if !r.DryRun {
+			t.Errorf("result for %s is not marked as a dry run\n%s", r.name(), buf)
+		}
+	}
+}
+
+func TestProviderDowngradedFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-testing-report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	reportFile := filepath.Join(dir, "report.tap")
+
+	wd := testHelper.RequireNewWorkingDir(t)
+	defer wd.Close()
+
+	wd.RequireSetConfig(t, fmt.Sprintf(`
+provider "testing" {
+  report_file   = %q
+  report_format = "tap"
+
+  severity_overrides = {
+    "Test failure" = "warning"
+  }
+}
+
+data "testing_assertions" "fail" {
+  check "a" {
+    expect = false
+  }
+}
+`, reportFile))
+
+	wd.RequireInit(t)
+	wd.RequireApply(t)
+
+	buf, err := ioutil.ReadFile(reportFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "not ok 1 - testing_assertions # TODO downgraded\n"; !strings.Contains(string(buf), want) {
+		t.Errorf("report does not contain %q\n%s", want, buf)
+	}
+}
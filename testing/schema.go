@@ -0,0 +1,112 @@
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/apparentlymart/terraform-sdk/tfschema"
+)
+
+// ProviderSchemaJSON returns a JSON description of the provider's
+// configuration schema and the schemas of its data sources, in the same
+// shape as a single provider's entry in the output of
+// "terraform providers schema -json".
+func ProviderSchemaJSON() ([]byte, error) {
+	ret := providerSchemaJSON{
+		Provider:          schemaJSON{Block: blockSchemaJSON(Provider().ConfigSchema)},
+		DataSourceSchemas: make(map[string]schemaJSON, len(dataResourceTypes)),
+	}
+	for name, fn := range dataResourceTypes {
+		rt, ok := fn().(dataResourceType)
+		if !ok {
+			return nil, fmt.Errorf("%s: not constructed by newDataResourceType", name)
+		}
+		ret.DataSourceSchemas[name] = schemaJSON{Block: blockSchemaJSON(rt.schema)}
+	}
+	return json.MarshalIndent(ret, "", "  ")
+}
+
+type providerSchemaJSON struct {
+	Provider          schemaJSON            `json:"provider"`
+	DataSourceSchemas map[string]schemaJSON `json:"data_source_schemas"`
+}
+
+type schemaJSON struct {
+	Version int64      `json:"version"`
+	Block   *blockJSON `json:"block"`
+}
+
+type blockJSON struct {
+	Attributes map[string]*attributeJSON `json:"attributes,omitempty"`
+	BlockTypes map[string]*blockTypeJSON `json:"block_types,omitempty"`
+}
+
+type attributeJSON struct {
+	Type        json.RawMessage `json:"type"`
+	Description string          `json:"description,omitempty"`
+	Required    bool            `json:"required,omitempty"`
+	Optional    bool            `json:"optional,omitempty"`
+	Computed    bool            `json:"computed,omitempty"`
+	Sensitive   bool            `json:"sensitive,omitempty"`
+}
+
+type blockTypeJSON struct {
+	NestingMode string     `json:"nesting_mode"`
+	Block       *blockJSON `json:"block"`
+	MinItems    int        `json:"min_items,omitempty"`
+	MaxItems    int        `json:"max_items,omitempty"`
+}
+
+func blockSchemaJSON(schema *tfschema.BlockType) *blockJSON {
+	ret := &blockJSON{}
+	if schema == nil {
+		return ret
+	}
+	for name, attr := range schema.Attributes {
+		ty, err := attr.Type.MarshalJSON()
+		if err != nil {
+			// Should never happen, since schema types are always valid.
+			panic(fmt.Sprintf("failed to serialize type of %q: %s", name, err))
+		}
+		if ret.Attributes == nil {
+			ret.Attributes = make(map[string]*attributeJSON)
+		}
+		ret.Attributes[name] = &attributeJSON{
+			Type:        ty,
+			Description: attr.Description,
+			Required:    attr.Required,
+			Optional:    attr.Optional,
+			// The SDK treats attributes with defaults as computed too.
+			Computed:  attr.Computed || attr.Default != nil,
+			Sensitive: attr.Sensitive,
+		}
+	}
+	for name, block := range schema.NestedBlockTypes {
+		if ret.BlockTypes == nil {
+			ret.BlockTypes = make(map[string]*blockTypeJSON)
+		}
+		ret.BlockTypes[name] = &blockTypeJSON{
+			NestingMode: nestingModeJSON(block.Nesting),
+			Block:       blockSchemaJSON(&block.Content),
+			MinItems:    block.MinItems,
+			MaxItems:    block.MaxItems,
+		}
+	}
+	return ret
+}
+
+func nestingModeJSON(mode tfschema.NestingMode) string {
+	switch mode {
+	case tfschema.NestingSingle:
+		return "single"
+	case tfschema.NestingList:
+		return "list"
+	case tfschema.NestingMap:
+		return "map"
+	case tfschema.NestingSet:
+		return "set"
+	default:
+		// Should never happen because the above is exhaustive.
+		panic(fmt.Sprintf("unsupported block nesting mode %#v", mode))
+	}
+}
@@ -0,0 +1,62 @@
+package testing
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestProviderSchemaJSON(t *testing.T) {
+	src, err := ProviderSchemaJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Provider struct {
+			Block struct {
+				Attributes map[string]struct {
+					Type     interface{} `json:"type"`
+					Optional bool        `json:"optional"`
+				} `json:"attributes"`
+			} `json:"block"`
+		} `json:"provider"`
+		DataSourceSchemas map[string]struct {
+			Block struct {
+				Attributes map[string]struct {
+					Type interface{} `json:"type"`
+				} `json:"attributes"`
+				BlockTypes map[string]struct {
+					NestingMode string `json:"nesting_mode"`
+					Block       struct {
+						Attributes map[string]struct {
+							Required bool `json:"required"`
+						} `json:"attributes"`
+					} `json:"block"`
+				} `json:"block_types"`
+			} `json:"block"`
+		} `json:"data_source_schemas"`
+	}
+	if err := json.Unmarshal(src, &got); err != nil {
+		t.Fatalf("invalid JSON: %s\n%s", err, src)
+	}
+
+	if len(got.DataSourceSchemas) != len(dataResourceTypes) {
+		t.Errorf("wrong number of data sources %d; want %d", len(got.DataSourceSchemas), len(dataResourceTypes))
+	}
+	if attr, ok := got.Provider.Block.Attributes["fail_fast"]; !ok || attr.Type != "bool" || !attr.Optional {
+		t.Errorf("wrong provider fail_fast attribute %#v", attr)
+	}
+	assertions, ok := got.DataSourceSchemas["testing_assertions"]
+	if !ok {
+		t.Fatalf("no schema for testing_assertions")
+	}
+	if ty := assertions.Block.Attributes["subject"].Type; ty != "string" {
+		t.Errorf("wrong subject type %#v; want \"string\"", ty)
+	}
+	check := assertions.Block.BlockTypes["check"]
+	if check.NestingMode != "map" {
+		t.Errorf("wrong check nesting mode %q; want \"map\"", check.NestingMode)
+	}
+	if !check.Block.Attributes["expect"].Required {
+		t.Errorf("check.expect is not required")
+	}
+}
@@ -0,0 +1,10 @@
+//go:build cgo
+// +build cgo
+
+package testing
+
+// The sqlite3 driver is a binding to the SQLite C library, so it is only
+// available when the provider is built with cgo.
+import _ "github.com/mattn/go-sqlite3"
+
+const sqliteAvailable = true
@@ -0,0 +1,8 @@
+//go:build !cgo
+// +build !cgo
+
+package testing
+
+// Without cgo, the sqlite3 driver would register only a stub that fails
+// to connect, so testing_sql reports that it isn't available instead.
+const sqliteAvailable = false
@@ -0,0 +1,39 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// defaultTerraformProgram is the Terraform CLI command used by data sources
+// that run Terraform when the configuration doesn't specify one.
+var defaultTerraformProgram = []string{"terraform"}
+
+// runTerraform runs the given Terraform CLI subcommand in the given directory
+// and returns what it wrote to stdout. If the command fails, the returned
+// error includes anything it wrote to stderr.
+//
+// program is the command to run Terraform, which may include additional
+// arguments that are placed before args. If it is empty, the default
+// "terraform" command is used.
+func runTerraform(ctx context.Context, client *Client, program []string, environment map[string]string, dir string, args ...string) ([]byte, error) {
+	if len(program) == 0 {
+		program = defaultTerraformProgram
+	}
+	cmdArgs := append(append([]string(nil), program...), args...)
+	cmd := programCommand(ctx, client, cmdArgs, environment)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	release, err := client.acquireProgram(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	out, err := cmd.Output()
+	if err != nil && stderr.Len() != 0 {
+		err = fmt.Errorf("%s\n\n%s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return out, err
+}
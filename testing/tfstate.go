@@ -0,0 +1,130 @@
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// tfState is the subset of the Terraform state snapshot format (version 4,
+// as used by Terraform 0.12 and later) that testing_terraform_state exposes.
+type tfState struct {
+	Version          int                      `json:"version"`
+	TerraformVersion string                   `json:"terraform_version"`
+	Serial           int64                    `json:"serial"`
+	Lineage          string                   `json:"lineage"`
+	Outputs          map[string]tfStateOutput `json:"outputs"`
+	Resources        []tfStateResource        `json:"resources"`
+}
+
+type tfStateOutput struct {
+	Value json.RawMessage `json:"value"`
+	Type  json.RawMessage `json:"type"`
+}
+
+type tfStateResource struct {
+	Module    string                    `json:"module"`
+	Mode      string                    `json:"mode"`
+	Type      string                    `json:"type"`
+	Name      string                    `json:"name"`
+	Instances []tfStateResourceInstance `json:"instances"`
+}
+
+type tfStateResourceInstance struct {
+	IndexKey   interface{}     `json:"index_key"`
+	Attributes json.RawMessage `json:"attributes"`
+}
+
+// parseTFState parses a Terraform state snapshot in JSON format.
+func parseTFState(src []byte) (*tfState, error) {
+	var state tfState
+	if err := json.Unmarshal(src, &state); err != nil {
+		return nil, err
+	}
+	if state.Version != 4 {
+		return nil, fmt.Errorf("unsupported state format version %d; only version 4, from Terraform 0.12 and later, is supported", state.Version)
+	}
+	return &state, nil
+}
+
+// OutputValues returns the root module output values as an object, using
+// the type information recorded in the state.
+func (s *tfState) OutputValues() (cty.Value, error) {
+	attrs := make(map[string]cty.Value, len(s.Outputs))
+	for name, output := range s.Outputs {
+		ty, err := ctyjson.UnmarshalType(output.Type)
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("output %q has invalid type: %s", name, err)
+		}
+		v, err := ctyjson.Unmarshal(output.Value, ty)
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("output %q has invalid value: %s", name, err)
+		}
+		attrs[name] = v
+	}
+	return cty.ObjectVal(attrs), nil
+}
+
+// ResourceInstances returns the attributes of each resource instance as an
+// object whose attribute names are resource instance addresses, such as
+// "module.network.aws_vpc.main" or "aws_instance.web[0]".
+//
+// The state doesn't record the types of resource attributes, so they are
+// decoded in the same way as Terraform's jsondecode function.
+func (s *tfState) ResourceInstances() (cty.Value, error) {
+	attrs := make(map[string]cty.Value)
+	for _, rs := range s.Resources {
+		addr := rs.Type + "." + rs.Name
+		if rs.Mode == "data" {
+			addr = "data." + addr
+		}
+		if rs.Module != "" {
+			addr = rs.Module + "." + addr
+		}
+		for _, inst := range rs.Instances {
+			instAddr := addr
+			switch key := inst.IndexKey.(type) {
+			case float64:
+				instAddr += "[" + strconv.FormatFloat(key, 'f', -1, 64) + "]"
+			case string:
+				instAddr += "[" + strconv.Quote(key) + "]"
+			}
+
+			v := cty.EmptyObjectVal
+			if len(inst.Attributes) != 0 && string(inst.Attributes) != "null" {
+				ty, err := ctyjson.ImpliedType(inst.Attributes)
+				if err == nil {
+					v, err = ctyjson.Unmarshal(inst.Attributes, ty)
+				}
+				if err != nil {
+					return cty.NilVal, fmt.Errorf("%s has invalid attributes: %s", instAddr, err)
+				}
+			}
+			attrs[instAddr] = v
+		}
+	}
+	return cty.ObjectVal(attrs), nil
+}
+
+// tfStateAddrs returns the keys of the given object in lexical order.
+func tfStateAddrs(v cty.Value) []string {
+	var ret []string
+	for it := v.ElementIterator(); it.Next(); {
+		k, _ := it.Element()
+		ret = append(ret, k.AsString())
+	}
+	if ret == nil {
+		ret = []string{}
+	}
+	return ret
+}
+
+// isTFStateMissing reports whether the output of "terraform state pull"
+// indicates that there is no state yet.
+func isTFStateMissing(src []byte) bool {
+	return len(strings.TrimSpace(string(src))) == 0
+}
@@ -0,0 +1,82 @@
+package testing
+
+import (
+	"regexp"
+	"time"
+
+	tfsdk "github.com/apparentlymart/terraform-sdk"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// validateDuration is a ValidateFn for arguments that expect a duration
+// string using the syntax accepted by Go's time.ParseDuration, like "30s".
+func validateDuration(v string) tfsdk.Diagnostics {
+	var diags tfsdk.Diagnostics
+	d, err := time.ParseDuration(v)
+	switch {
+	case err != nil:
+		diags = diags.Append(tfsdk.ValidationError(
+			cty.Path(nil).NewErrorf("must be a duration string, such as \"30s\" or \"5m\""),
+		))
+	case d < 0:
+		diags = diags.Append(tfsdk.ValidationError(
+			cty.Path(nil).NewErrorf("must not be negative"),
+		))
+	}
+	return diags
+}
+
+// validatePositiveDuration is like validateDuration, but also rejects a
+// zero duration, for arguments such as polling intervals where zero would
+// mean trying again without any delay.
+func validatePositiveDuration(v string) tfsdk.Diagnostics {
+	diags := validateDuration(v)
+	if diags.HasErrors() {
+		return diags
+	}
+	if d, _ := time.ParseDuration(v); d == 0 {
+		diags = diags.Append(tfsdk.ValidationError(
+			cty.Path(nil).NewErrorf("must be greater than zero"),
+		))
+	}
+	return diags
+}
+
+// durationOrDefault parses the given duration string, which must already have
+// been checked using validateDuration, or returns the given default duration
+// if the string pointer is nil.
+func durationOrDefault(raw *string, def time.Duration) time.Duration {
+	if raw == nil {
+		return def
+	}
+	// We already validated the duration during the validate step, so we can
+	// safely ignore errors here.
+	d, _ := time.ParseDuration(*raw)
+	return d
+}
+
+// validateRegexp is a ValidateFn for arguments that expect a regular
+// expression using the RE2 syntax accepted by Go's regexp package.
+func validateRegexp(v string) tfsdk.Diagnostics {
+	var diags tfsdk.Diagnostics
+	if _, err := regexp.Compile(v); err != nil {
+		diags = diags.Append(tfsdk.ValidationError(
+			cty.Path(nil).NewErrorf("must be a valid regular expression: %s", err),
+		))
+	}
+	return diags
+}
+
+// validateRegexps is a ValidateFn for arguments that expect a list of
+// regular expressions.
+func validateRegexps(v []string) tfsdk.Diagnostics {
+	var diags tfsdk.Diagnostics
+	for i, p := range v {
+		if _, err := regexp.Compile(p); err != nil {
+			diags = diags.Append(tfsdk.ValidationError(
+				cty.Path(nil).Index(cty.NumberIntVal(int64(i))).NewErrorf("must be a valid regular expression: %s", err),
+			))
+		}
+	}
+	return diags
+}
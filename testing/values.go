@@ -117,3 +117,21 @@ func formatSequenceValue(v cty.Value, indent int) string {
 	buf.WriteByte(']')
 	return buf.String()
 }
+
+// namedAssertionFailureMsg returns a failure message for an assertion that
+// compares a single named aspect of a result, such as its count or value.
+func namedAssertionFailureMsg(statement, what string, want, got cty.Value) string {
+	if statement != "" {
+		return fmt.Sprintf(
+			"Assertion failed: %s.\n  Want %s: %s\n  Got %s:  %s",
+			statement,
+			what, formatValue(want, 2),
+			what, formatValue(got, 2),
+		)
+	}
+	return fmt.Sprintf(
+		"Assertion failed.\n  Want %s: %s\n  Got %s:  %s",
+		what, formatValue(want, 2),
+		what, formatValue(got, 2),
+	)
+}
@@ -0,0 +1,250 @@
+package testing
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/antchfx/xpath"
+)
+
+// xmlNode is a node in a simple XML document tree, used as the data model
+// for XPath evaluation.
+type xmlNode struct {
+	Type  xpath.NodeType
+	Name  xml.Name // Space is the namespace prefix, not the namespace URL
+	Data  string   // for text and comment nodes only
+	Attrs []xml.Attr
+
+	Parent, FirstChild, LastChild, PrevSibling, NextSibling *xmlNode
+}
+
+func (n *xmlNode) appendChild(child *xmlNode) {
+	child.Parent = n
+	if n.LastChild == nil {
+		n.FirstChild = child
+	} else {
+		n.LastChild.NextSibling = child
+		child.PrevSibling = n.LastChild
+	}
+	n.LastChild = child
+}
+
+// stringValue returns the XPath "string-value" of the node, which for
+// elements and the root node is the concatenation of all of the descendent
+// text nodes.
+func (n *xmlNode) stringValue() string {
+	switch n.Type {
+	case xpath.TextNode, xpath.CommentNode:
+		return n.Data
+	default:
+		var buf strings.Builder
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == xpath.CommentNode {
+				continue
+			}
+			buf.WriteString(c.stringValue())
+		}
+		return buf.String()
+	}
+}
+
+// parseXML parses the given XML source into a tree of nodes, returning the
+// root node.
+//
+// Namespace prefixes are retained literally rather than resolved, so that
+// XPath expressions can refer to elements using the same prefixes that
+// appear in the document.
+func parseXML(src string) (*xmlNode, error) {
+	root := &xmlNode{Type: xpath.RootNode}
+	current := root
+	dec := xml.NewDecoder(strings.NewReader(src))
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if current == root && root.hasElementChild() {
+				return nil, fmt.Errorf("document has more than one root element")
+			}
+			n := &xmlNode{
+				Type:  xpath.ElementNode,
+				Name:  tok.Name,
+				Attrs: append([]xml.Attr(nil), tok.Attr...),
+			}
+			current.appendChild(n)
+			current = n
+		case xml.EndElement:
+			if current == root || current.Name != tok.Name {
+				return nil, fmt.Errorf("unexpected end element </%s> on line %d", formatXMLName(tok.Name), lineOfOffset(src, dec.InputOffset()))
+			}
+			current = current.Parent
+		case xml.CharData:
+			if current == root {
+				if strings.TrimSpace(string(tok)) != "" {
+					return nil, fmt.Errorf("unexpected text outside of the root element on line %d", lineOfOffset(src, dec.InputOffset()))
+				}
+				continue
+			}
+			current.appendChild(&xmlNode{
+				Type: xpath.TextNode,
+				Data: string(tok),
+			})
+		case xml.Comment:
+			current.appendChild(&xmlNode{
+				Type: xpath.CommentNode,
+				Data: string(tok),
+			})
+		}
+	}
+	if current != root {
+		return nil, fmt.Errorf("unexpected end of document while element <%s> is still open", formatXMLName(current.Name))
+	}
+	if !root.hasElementChild() {
+		return nil, fmt.Errorf("document has no root element")
+	}
+	return root, nil
+}
+
+func (n *xmlNode) hasElementChild() bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == xpath.ElementNode {
+			return true
+		}
+	}
+	return false
+}
+
+func formatXMLName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+func lineOfOffset(src string, offset int64) int {
+	if offset > int64(len(src)) {
+		offset = int64(len(src))
+	}
+	return strings.Count(src[:offset], "\n") + 1
+}
+
+// xmlNavigator is an implementation of xpath.NodeNavigator for a tree of
+// xmlNode.
+type xmlNavigator struct {
+	root, current *xmlNode
+	attr          int // index into current.Attrs, or -1 if not on an attribute
+}
+
+var _ xpath.NodeNavigator = (*xmlNavigator)(nil)
+
+func newXMLNavigator(root *xmlNode) *xmlNavigator {
+	return &xmlNavigator{root: root, current: root, attr: -1}
+}
+
+func (nav *xmlNavigator) NodeType() xpath.NodeType {
+	if nav.attr != -1 {
+		return xpath.AttributeNode
+	}
+	return nav.current.Type
+}
+
+func (nav *xmlNavigator) LocalName() string {
+	if nav.attr != -1 {
+		return nav.current.Attrs[nav.attr].Name.Local
+	}
+	return nav.current.Name.Local
+}
+
+func (nav *xmlNavigator) Prefix() string {
+	if nav.attr != -1 {
+		return nav.current.Attrs[nav.attr].Name.Space
+	}
+	return nav.current.Name.Space
+}
+
+func (nav *xmlNavigator) Value() string {
+	if nav.attr != -1 {
+		return nav.current.Attrs[nav.attr].Value
+	}
+	return nav.current.stringValue()
+}
+
+func (nav *xmlNavigator) Copy() xpath.NodeNavigator {
+	ret := *nav
+	return &ret
+}
+
+func (nav *xmlNavigator) MoveToRoot() {
+	nav.current = nav.root
+	nav.attr = -1
+}
+
+func (nav *xmlNavigator) MoveToParent() bool {
+	if nav.attr != -1 {
+		nav.attr = -1
+		return true
+	}
+	if nav.current.Parent == nil {
+		return false
+	}
+	nav.current = nav.current.Parent
+	return true
+}
+
+func (nav *xmlNavigator) MoveToNextAttribute() bool {
+	if nav.attr >= len(nav.current.Attrs)-1 {
+		return false
+	}
+	nav.attr++
+	return true
+}
+
+func (nav *xmlNavigator) MoveToChild() bool {
+	if nav.attr != -1 || nav.current.FirstChild == nil {
+		return false
+	}
+	nav.current = nav.current.FirstChild
+	return true
+}
+
+func (nav *xmlNavigator) MoveToFirst() bool {
+	if nav.attr != -1 || nav.current.PrevSibling == nil {
+		return false
+	}
+	nav.current = nav.current.Parent.FirstChild
+	return true
+}
+
+func (nav *xmlNavigator) MoveToNext() bool {
+	if nav.attr != -1 || nav.current.NextSibling == nil {
+		return false
+	}
+	nav.current = nav.current.NextSibling
+	return true
+}
+
+func (nav *xmlNavigator) MoveToPrevious() bool {
+	if nav.attr != -1 || nav.current.PrevSibling == nil {
+		return false
+	}
+	nav.current = nav.current.PrevSibling
+	return true
+}
+
+func (nav *xmlNavigator) MoveTo(other xpath.NodeNavigator) bool {
+	o, ok := other.(*xmlNavigator)
+	if !ok || o.root != nav.root {
+		return false
+	}
+	nav.current = o.current
+	nav.attr = o.attr
+	return true
+}